@@ -1,3 +1,6 @@
+//go:build !windows
+// +build !windows
+
 package env
 
 // LineBreak is the platform specific linebreak as a string.
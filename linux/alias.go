@@ -0,0 +1,46 @@
+package linux
+
+import (
+	"strings"
+	"sync"
+)
+
+// distroAliases maps the IDs of custom distros (eg in-house rebuilds) to the ID of the distro family
+// that they are based on.
+var distroAliases = map[string]string{}
+var distroAliasesLock sync.RWMutex
+
+// RegisterAlias registers a custom distro ID (eg acmecorp-linux) as belonging to the family of a known
+// distro ID (eg rhel or ubuntu), so that the family checks (eg UsesRPM, IsRHELCompatible and
+// PackageManager) treat the custom distro as the known distro. Aliases apply to both the ID and the IDs
+// in ID_LIKE.
+func RegisterAlias(id string, baseFamily string) {
+	distroAliasesLock.Lock()
+	defer distroAliasesLock.Unlock()
+
+	distroAliases[strings.ToLower(id)] = strings.ToLower(baseFamily)
+}
+
+// aliasedID returns the family registered for the supplied ID or the ID itself when no alias is
+// registered.
+func aliasedID(id string) string {
+	distroAliasesLock.RLock()
+	defer distroAliasesLock.RUnlock()
+
+	if baseFamily, ok := distroAliases[strings.ToLower(id)]; ok {
+		return baseFamily
+	}
+
+	return id
+}
+
+// likeIDs returns the ID of the distro followed by the IDs in ID_LIKE with any registered aliases
+// resolved.
+func (l *LinuxDistro) likeIDs() []string {
+	likeIds := append([]string{l.ID}, strings.Fields(l.OsRelease["ID_LIKE"])...)
+	for i, likeId := range likeIds {
+		likeIds[i] = aliasedID(likeId)
+	}
+
+	return likeIds
+}
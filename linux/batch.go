@@ -0,0 +1,188 @@
+package linux
+
+import (
+	"encoding/json"
+	"github.com/dekobon/distro-detect/env"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// BatchConcurrency is the maximum number of filesystem roots that DiscoverDistros inspects at once.
+var BatchConcurrency = runtime.NumCPU()
+
+// RootDistro is the distro detected for a single filesystem root.
+type RootDistro struct {
+	Root   string      `json:"root"`
+	Distro LinuxDistro `json:"distro"`
+}
+
+// DiscoverDistros detects the distro of each of the supplied filesystem roots using a pool of at
+// most BatchConcurrency workers. The results are returned in the same order as the roots.
+func DiscoverDistros(roots []string) []RootDistro {
+	results := make([]RootDistro, len(roots))
+
+	discoverDistrosEach(roots, func(index int, result RootDistro) {
+		results[index] = result
+	})
+
+	return results
+}
+
+// DiscoverDistrosUnder detects the distro of each of the supplied subdirectories of parent (eg the
+// root partitions of a multi-boot disk image mounted under a single directory) and returns the
+// distros keyed by subdirectory. When no subdirectories are supplied, every subdirectory of parent
+// that contains an os-release file is inspected.
+func DiscoverDistrosUnder(parent string, subdirs []string) map[string]LinuxDistro {
+	if len(subdirs) == 0 {
+		subdirs = subdirsWithOsRelease(parent)
+	}
+
+	roots := make([]string, len(subdirs))
+	for i, subdir := range subdirs {
+		roots[i] = filepath.Join(parent, subdir)
+	}
+
+	distros := make(map[string]LinuxDistro, len(subdirs))
+	for i, result := range DiscoverDistros(roots) {
+		distros[subdirs[i]] = result.Distro
+	}
+
+	return distros
+}
+
+// subdirsWithOsRelease returns the names of the subdirectories of parent that contain an os-release
+// file in any of its locations.
+func subdirsWithOsRelease(parent string) []string {
+	fileInfos, err := ioutil.ReadDir(parent)
+	if err != nil {
+		LogWarnf("unable to read directory (%s): %v", parent, err)
+		return nil
+	}
+
+	var subdirs []string
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() {
+			continue
+		}
+
+		detector := &Detector{Root: filepath.Join(parent, fileInfo.Name())}
+		for _, osReleasePath := range osReleasePaths {
+			if _, err := detector.stat(osReleasePath); err == nil {
+				subdirs = append(subdirs, fileInfo.Name())
+				break
+			}
+		}
+	}
+
+	return subdirs
+}
+
+// WriteDistrosJSON detects the distro of each of the supplied filesystem roots like DiscoverDistros
+// and writes the results to the supplied writer as a JSON array. Each result is written as soon as
+// it is detected, so the results are written in the order in which detection completed and memory
+// use doesn't grow with the number of roots.
+func WriteDistrosJSON(writer io.Writer, roots []string) error {
+	results := make(chan RootDistro)
+	go func() {
+		discoverDistrosEach(roots, func(_ int, result RootDistro) {
+			results <- result
+		})
+		close(results)
+	}()
+
+	encoder := NewJSONArrayEncoder(writer)
+	var err error
+	for result := range results {
+		// Keep receiving after a failed write so that the workers are able to finish
+		if err == nil {
+			err = encoder.Encode(result)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// discoverDistrosEach detects the distro of each of the supplied filesystem roots using a pool of at
+// most BatchConcurrency workers and passes each result along with the index of its root to onResult.
+// onResult is called concurrently from the workers.
+func discoverDistrosEach(roots []string, onResult func(int, RootDistro)) {
+	concurrency := BatchConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency && i < len(roots); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				detector := WithRoot(roots[index])
+				onResult(index, RootDistro{
+					Root:   roots[index],
+					Distro: detector.DiscoverDistro(),
+				})
+			}
+		}()
+	}
+
+	for i := range roots {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// JSONArrayEncoder writes values to a writer as the elements of a JSON array as they are encoded,
+// rather than collecting them into a slice first. Close must be called to terminate the array.
+type JSONArrayEncoder struct {
+	writer  io.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+// NewJSONArrayEncoder creates a new JSONArrayEncoder that writes to the supplied writer.
+func NewJSONArrayEncoder(writer io.Writer) *JSONArrayEncoder {
+	return &JSONArrayEncoder{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// Encode writes the JSON encoding of the supplied value as the next element of the array.
+func (e *JSONArrayEncoder) Encode(value interface{}) error {
+	separator := ","
+	if e.count == 0 {
+		separator = "["
+	}
+
+	if _, err := io.WriteString(e.writer, separator); err != nil {
+		return err
+	}
+	if err := e.encoder.Encode(value); err != nil {
+		return err
+	}
+	e.count++
+
+	return nil
+}
+
+// Close terminates the array. An empty array is written when no values were encoded.
+func (e *JSONArrayEncoder) Close() error {
+	terminator := "]"
+	if e.count == 0 {
+		terminator = "[]"
+	}
+
+	_, err := io.WriteString(e.writer, terminator+env.LineBreak)
+	return err
+}
@@ -0,0 +1,43 @@
+package linux
+
+import "sync"
+
+// cachedDistro is the distro detected for a single filesystem root by DiscoverDistroCached.
+type cachedDistro struct {
+	once   sync.Once
+	distro LinuxDistro
+}
+
+var distroCache = map[string]*cachedDistro{}
+var distroCacheLock sync.Mutex
+
+// DiscoverDistroCached detects the distro of the filesystem at FileSystemRoot once and returns the
+// same result on subsequent calls, which is useful for long-running programs that detect the distro
+// repeatedly. Results are cached separately for each value of FileSystemRoot. It is safe to call
+// concurrently.
+func DiscoverDistroCached() LinuxDistro {
+	root := FileSystemRoot
+
+	distroCacheLock.Lock()
+	cached, ok := distroCache[root]
+	if !ok {
+		cached = &cachedDistro{}
+		distroCache[root] = cached
+	}
+	distroCacheLock.Unlock()
+
+	cached.once.Do(func() {
+		cached.distro = WithRoot(root).DiscoverDistro()
+	})
+
+	return cached.distro
+}
+
+// InvalidateCache discards the distros cached by DiscoverDistroCached, so that the next call detects
+// the distro again (eg after the system was upgraded).
+func InvalidateCache() {
+	distroCacheLock.Lock()
+	defer distroCacheLock.Unlock()
+
+	distroCache = map[string]*cachedDistro{}
+}
@@ -0,0 +1,43 @@
+package linux
+
+import "strings"
+
+// codenameVersions maps distro IDs to the version of each release codename of the distro. It is used
+// to determine the version of minimal installations that only report the release codename.
+var codenameVersions = map[string]map[string]string{
+	"debian": {
+		"wheezy":   "7",
+		"jessie":   "8",
+		"stretch":  "9",
+		"buster":   "10",
+		"bullseye": "11",
+		"bookworm": "12",
+		"trixie":   "13",
+	},
+	"ubuntu": {
+		"trusty":   "14.04",
+		"xenial":   "16.04",
+		"bionic":   "18.04",
+		"cosmic":   "18.10",
+		"disco":    "19.04",
+		"eoan":     "19.10",
+		"focal":    "20.04",
+		"groovy":   "20.10",
+		"hirsute":  "21.04",
+		"impish":   "21.10",
+		"jammy":    "22.04",
+		"kinetic":  "22.10",
+		"lunar":    "23.04",
+		"mantic":   "23.10",
+		"noble":    "24.04",
+		"oracular": "24.10",
+		"plucky":   "25.04",
+		"questing": "25.10",
+	},
+}
+
+// codenameVersion returns the version of the supplied release codename of the distro with the supplied
+// ID or an empty string when the codename isn't known.
+func codenameVersion(id string, codename string) string {
+	return codenameVersions[id][strings.ToLower(strings.TrimSpace(codename))]
+}
@@ -0,0 +1,586 @@
+package linux
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrDistroNotDetected is returned by DiscoverDistroE when none of the tests in DistroTests matched
+// and the distro returned is a best guess.
+var ErrDistroNotDetected = errors.New("distro not detected")
+
+// ErrInvalidRoot is returned by DiscoverDistroE when the detector's root doesn't exist or isn't a
+// directory.
+var ErrInvalidRoot = errors.New("invalid filesystem root")
+
+// DetectionResult is a detected distro along with the diagnostics gathered while detecting it.
+type DetectionResult struct {
+	Distro LinuxDistro
+	// Warnings contains the issues encountered during detection that did not prevent detection.
+	Warnings []string
+}
+
+// Detector holds the state for detecting the distro of a single filesystem root.
+type Detector struct {
+	// Root is the path to the root of the filesystem in which to detect the distro.
+	Root string
+	// RecordInspectedPaths enables recording of every path that the detector attempts to read.
+	RecordInspectedPaths bool
+	// HashReleaseFiles enables the recording of the SHA-256 hash of every file that the detector reads
+	// in its entirety. The hashes are returned in LinuxDistro.ReleaseFileHashes.
+	HashReleaseFiles bool
+	// Debug enables logging of the result of every detector in DistroTests along with the files that
+	// each detector read.
+	Debug bool
+	// CandidatePaths overrides the release file paths that a detector attempts to read, keyed by the
+	// name of the detector (eg "IsSLES"). The paths of the os-release and lsb-release files that are
+	// read before any detector runs are keyed by "os-release" and "lsb-release". Detectors without an
+	// entry read their built-in paths.
+	CandidatePaths map[string][]string
+	// OnDetectorRun is called with the name and duration of each detector in DistroTests that runs when
+	// it is set.
+	OnDetectorRun func(name string, duration time.Duration)
+	// OnFileRead is called with the path and duration of each file read (from opening the file until
+	// it is closed) when it is set. Files that don't exist aren't reported.
+	OnFileRead func(filePath string, duration time.Duration)
+	// NormalizeIDs enables mapping of the detected ID to its canonical ID in CanonicalIDs (eg ol to
+	// oracle). When it is disabled, the ID reported by the distro is preserved.
+	NormalizeIDs bool
+	// AllowExec enables the detectors that sniff the contents of executables (eg the BusyBox test of
+	// /bin/true), which some sandboxed environments forbid. It is enabled by NewDetector and WithRoot.
+	AllowExec bool
+
+	inspectedPaths []string
+	// debugPaths are the paths read by the detector currently running when Debug is enabled.
+	debugPaths        []string
+	releaseFileHashes map[string]string
+	// warnings are only collected for the duration of DiscoverDistroE, otherwise they are logged.
+	collectWarnings bool
+	warnings        []string
+	// guessed is set when none of the distro tests matched during the last detection.
+	guessed bool
+	// ctx is only set for the duration of DiscoverDistroContext so that file reads can be cancelled.
+	ctx context.Context
+	// fsys replaces the OS filesystem when it is set.
+	fsys fileSystem
+}
+
+// NewDetector creates a new Detector that inspects the filesystem at FileSystemRoot.
+func NewDetector() *Detector {
+	return &Detector{
+		Root:      FileSystemRoot,
+		AllowExec: true,
+	}
+}
+
+// WithRoot creates a new Detector that inspects the filesystem at the supplied root. Unlike
+// NewDetector, the detector is unaffected by FileSystemRoot.
+func WithRoot(root string) *Detector {
+	return &Detector{
+		Root:      root,
+		AllowExec: true,
+	}
+}
+
+// DiscoverDistro detects the distro installed under the detector's root.
+func (d *Detector) DiscoverDistro() LinuxDistro {
+	if err := d.checkRoot(); err != nil {
+		LogErrorf("unable to detect distro: %v", err)
+		return unknownDistro()
+	}
+
+	distro, _ := d.discover()
+	return distro
+}
+
+// DiscoverDistroE detects the distro installed under the detector's root. Rather than logging
+// warnings, they are collected and returned in the result. An error is returned when a release
+// file exists but can't be read or parsed. When the distro couldn't be detected, the best guess is
+// returned along with ErrDistroNotDetected. When the root is invalid, nothing is detected and
+// ErrInvalidRoot is returned.
+func (d *Detector) DiscoverDistroE() (DetectionResult, error) {
+	if err := d.checkRoot(); err != nil {
+		return DetectionResult{Distro: unknownDistro()}, err
+	}
+
+	d.collectWarnings = true
+	d.warnings = nil
+	defer func() { d.collectWarnings = false }()
+
+	distro, err := d.discover()
+	if err == nil && d.guessed {
+		err = ErrDistroNotDetected
+	}
+
+	return DetectionResult{
+		Distro:   distro,
+		Warnings: d.warnings,
+	}, err
+}
+
+// DiscoverDistroContext detects the distro installed under the detector's root while aborting any
+// pending file reads when the supplied context is done. If the context is done before detection
+// completes, the context's error is returned along with whatever distro could be determined. When the
+// root is invalid, nothing is detected and ErrInvalidRoot is returned.
+func (d *Detector) DiscoverDistroContext(ctx context.Context) (LinuxDistro, error) {
+	if err := d.checkRoot(); err != nil {
+		return unknownDistro(), err
+	}
+
+	d.ctx = ctx
+	defer func() { d.ctx = nil }()
+
+	distro, _ := d.discover()
+	return distro, ctx.Err()
+}
+
+// checkRoot returns an error wrapping ErrInvalidRoot when the detector's root doesn't exist or isn't
+// a directory. Detectors backed by an io/fs.FS or without a root are not checked.
+func (d *Detector) checkRoot() error {
+	if d.fsys != nil || d.Root == "" {
+		return nil
+	}
+
+	info, err := os.Stat(d.Root)
+	if err != nil {
+		return fmt.Errorf("%w (%s): %v", ErrInvalidRoot, d.Root, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w (%s): not a directory", ErrInvalidRoot, d.Root)
+	}
+
+	return nil
+}
+
+// unknownDistro is the distro reported when nothing could be detected.
+func unknownDistro() LinuxDistro {
+	return LinuxDistro{
+		Name:            "Unknown",
+		ID:              "unknown",
+		Version:         "unknown",
+		DetectedAt:      time.Now().UTC(),
+		DetectorVersion: Version,
+	}
+}
+
+// InspectedPaths returns the paths (relative to Root) that the detector attempted to read during the
+// last detection in the order in which they were first inspected. Paths are only recorded when
+// RecordInspectedPaths is enabled.
+func (d *Detector) InspectedPaths() []string {
+	paths := make([]string, len(d.inspectedPaths))
+	copy(paths, d.inspectedPaths)
+	return paths
+}
+
+// DetectAll runs every test in DistroTests against the supplied properties and returns each distro
+// that matched in the order in which the tests ran. This is useful for debugging misdetections on
+// systems that impersonate other distros.
+func (d *Detector) DetectAll(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) []LinuxDistro {
+	var matches []LinuxDistro
+	detectorOsReleaseProperties := withSynthesizedID(osReleaseProperties)
+
+	for _, distroTest := range distroTestTable {
+		wasDetected, detectedDistro := distroTest.detect(d, lsbProperties, detectorOsReleaseProperties)
+
+		if wasDetected {
+			detectedDistro.OsRelease = osReleaseProperties
+			detectedDistro.DetectedAt = time.Now().UTC()
+			detectedDistro.DetectorVersion = Version
+			matches = append(matches, detectedDistro)
+		}
+	}
+
+	return matches
+}
+
+func (d *Detector) discoverDistroFromProperties(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) LinuxDistro {
+	var detectedDistro LinuxDistro
+	wasDetected := false
+
+	detectorOsReleaseProperties := withSynthesizedID(osReleaseProperties)
+
+	for _, distroTest := range distroTestTable {
+		d.debugPaths = nil
+		var start time.Time
+		if d.OnDetectorRun != nil {
+			start = time.Now()
+		}
+
+		wasDetected, detectedDistro = distroTest.detect(d, lsbProperties, detectorOsReleaseProperties)
+
+		if d.OnDetectorRun != nil {
+			d.OnDetectorRun(distroTest.name, time.Since(start))
+		}
+		if d.Debug {
+			LogDebugf("%s=%t files read: %v", distroTest.name, wasDetected, d.debugPaths)
+		}
+
+		if wasDetected {
+			break
+		}
+	}
+
+	d.guessed = !wasDetected
+	if wasDetected {
+		// The synthesized ID is only used for detection, the properties are reported as they were read
+		detectedDistro.OsRelease = osReleaseProperties
+	} else {
+		detectedDistro = bestGuess(d, lsbProperties, osReleaseProperties)
+	}
+
+	if detectedDistro.ReportedID == "" {
+		detectedDistro.ReportedID = detectedDistro.reportedID()
+	}
+	if detectedDistro.Vendor == "" {
+		detectedDistro.Vendor = detectedDistro.vendor()
+	}
+	if detectedDistro.PrettyName == "" {
+		detectedDistro.PrettyName = detectedDistro.prettyName()
+	}
+	if detectedDistro.BuildID == "" {
+		detectedDistro.BuildID = osReleaseProperties["BUILD_ID"]
+	}
+	if detectedDistro.ImageID == "" {
+		detectedDistro.ImageID = osReleaseProperties["IMAGE_ID"]
+	}
+	if detectedDistro.ImageVersion == "" {
+		detectedDistro.ImageVersion = osReleaseProperties["IMAGE_VERSION"]
+	}
+	if detectedDistro.Edition == "" {
+		detectedDistro.Edition = detectedDistro.edition()
+	}
+	if d.NormalizeIDs {
+		normalizeID(&detectedDistro)
+	}
+	detectedDistro.DetectedAt = time.Now().UTC()
+	detectedDistro.DetectorVersion = Version
+
+	return detectedDistro
+}
+
+func (d *Detector) readBinaryFile(filePaths ...string) (io.ReadCloser, string, error) {
+	d.recordInspectedPaths(filePaths)
+	if d.ctx != nil && d.ctx.Err() != nil {
+		return nil, "", d.ctx.Err()
+	}
+
+	var start time.Time
+	if d.OnFileRead != nil {
+		start = time.Now()
+	}
+
+	reader, filePath, err := readBinaryFileFunc(d, filePaths)
+	if err != nil && filePath == "" {
+		reader, filePath, err = d.readCompressedOsRelease(filePaths, err)
+	}
+	if err != nil {
+		return reader, filePath, err
+	}
+
+	if d.OnFileRead != nil {
+		reader = &timingReadCloser{
+			reader: reader,
+			onClose: func() {
+				d.OnFileRead(filePath, time.Since(start))
+			},
+		}
+	}
+
+	if d.ctx != nil {
+		reader = &contextReadCloser{
+			ctx:    d.ctx,
+			reader: reader,
+		}
+	}
+
+	if !d.HashReleaseFiles {
+		return reader, filePath, nil
+	}
+
+	hashingReader := &hashingReadCloser{
+		reader: reader,
+		hash:   sha256.New(),
+		onComplete: func(sum string) {
+			d.recordReleaseFileHash(filePath, sum)
+		},
+	}
+
+	return hashingReader, filePath, nil
+}
+
+// readCompressedOsRelease opens the gzip compressed variant (eg /etc/os-release.gz) of the supplied
+// os-release paths, which some minimized container images provide in place of the os-release file.
+// The supplied error is returned when none of the compressed variants exist.
+func (d *Detector) readCompressedOsRelease(filePaths []string, notFoundErr error) (io.ReadCloser, string, error) {
+	var compressedPaths []string
+	for _, filePath := range filePaths {
+		for _, osReleasePath := range osReleasePaths {
+			if filePath == osReleasePath {
+				compressedPaths = append(compressedPaths, filePath+".gz")
+			}
+		}
+	}
+	if len(compressedPaths) == 0 {
+		return nil, "", notFoundErr
+	}
+
+	d.recordInspectedPaths(compressedPaths)
+	reader, filePath, err := readBinaryFileFunc(d, compressedPaths)
+	if err != nil {
+		if filePath == "" {
+			return nil, "", notFoundErr
+		}
+		return nil, filePath, err
+	}
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		_ = reader.Close()
+		return nil, filePath, fmt.Errorf("unable to decompress file (%s): %v", filePath, err)
+	}
+
+	return &gzipReadCloser{Reader: gzipReader, file: reader}, filePath, nil
+}
+
+func (d *Detector) readFile(filePaths ...string) (bool, string) {
+	d.recordInspectedPaths(filePaths)
+	return readFileFunc(d, filePaths...)
+}
+
+// readNonEmptyFile reads the first of the supplied files that exists like readFile, but treats a
+// file that is empty or only contains whitespace as though it doesn't exist.
+func (d *Detector) readNonEmptyFile(filePaths ...string) (bool, string) {
+	exists, contents := d.readFile(filePaths...)
+	if !exists || strings.TrimSpace(contents) == "" {
+		return false, ""
+	}
+
+	return true, contents
+}
+
+// candidatePaths returns the paths configured in CandidatePaths for the named detector or the supplied
+// default paths when none are configured.
+func (d *Detector) candidatePaths(name string, defaultPaths ...string) []string {
+	if paths, ok := d.CandidatePaths[name]; ok && len(paths) > 0 {
+		return paths
+	}
+
+	return defaultPaths
+}
+
+// discoverFromReleaseFiles detects the distro by reading the release files under the detector's root.
+func (d *Detector) discoverFromReleaseFiles() (LinuxDistro, error) {
+	// A detector may be reused, so only the paths and hashes of this detection are reported
+	d.inspectedPaths = nil
+	d.releaseFileHashes = nil
+
+	lsbProperties, lsbErr := readReleaseFile(d, d.candidatePaths("lsb-release", "/etc/lsb-release")...)
+	osReleaseProperties, osReleaseErr := readReleaseFile(d, d.candidatePaths("os-release", osReleasePaths...)...)
+
+	if len(osReleaseProperties) == 0 {
+		d.warnf("no os-release properties were found - relying on other release files")
+	}
+
+	distro := d.discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	distro.Libc = d.detectLibc(distro)
+	distro.HardwareModel = d.detectHardwareModel()
+	distro.Virtualization = d.detectVirtualization()
+	distro.UbuntuPro = d.detectUbuntuPro(distro)
+	distro.Edition = d.detectEdition(distro)
+	distro.ostreeBooted = d.detectOSTreeBooted()
+	if d.HashReleaseFiles {
+		distro.ReleaseFileHashes = make(map[string]string, len(d.releaseFileHashes))
+		for filePath, sum := range d.releaseFileHashes {
+			distro.ReleaseFileHashes[filePath] = sum
+		}
+	}
+
+	if osReleaseErr != nil {
+		return distro, osReleaseErr
+	}
+
+	return distro, lsbErr
+}
+
+// inspectsHostRoot returns true when the detector reads the root of the host filesystem rather than an
+// fs.FS or an explicit root such as a mounted image.
+func (d *Detector) inspectsHostRoot() bool {
+	if d.fsys != nil {
+		return false
+	}
+	if d.Root == "" {
+		return true
+	}
+
+	root := filepath.Clean(d.Root)
+	return root == filepath.VolumeName(root)+string(os.PathSeparator)
+}
+
+// rootedPath returns the supplied path relative to the detector's root. The supplied path is a slash
+// separated Linux path, while the root and the returned path use the separator of the host OS so that
+// an image mounted on a Windows host (eg under D:\images\root) can be inspected.
+func (d *Detector) rootedPath(filePath string) string {
+	if d.Root == string(os.PathSeparator) {
+		return filePath
+	}
+
+	return filepath.Join(d.Root, filepath.FromSlash(filePath))
+}
+
+// readDir returns the names of the files (excluding directories) in the supplied directory.
+func (d *Detector) readDir(dirPath string) ([]string, error) {
+	d.recordInspectedPaths([]string{dirPath})
+	return readDirFunc(d, dirPath)
+}
+
+func (d *Detector) warnf(format string, args ...interface{}) {
+	if !d.collectWarnings {
+		LogWarnf(format, args...)
+		return
+	}
+
+	d.warnings = append(d.warnings, fmt.Sprintf(format, args...))
+}
+
+func (d *Detector) recordInspectedPaths(filePaths []string) {
+	if d.Debug {
+		d.debugPaths = append(d.debugPaths, filePaths...)
+	}
+
+	if !d.RecordInspectedPaths {
+		return
+	}
+
+	for _, filePath := range filePaths {
+		alreadyRecorded := false
+		for _, inspected := range d.inspectedPaths {
+			if inspected == filePath {
+				alreadyRecorded = true
+				break
+			}
+		}
+
+		if !alreadyRecorded {
+			d.inspectedPaths = append(d.inspectedPaths, filePath)
+		}
+	}
+}
+
+func (d *Detector) recordReleaseFileHash(filePath string, sum string) {
+	if d.releaseFileHashes == nil {
+		d.releaseFileHashes = map[string]string{}
+	}
+
+	// Hashes are keyed by the path relative to the root, so that they are comparable across roots
+	if d.Root != "" && d.Root != string(os.PathSeparator) {
+		filePath = filepath.ToSlash(strings.TrimPrefix(filePath, filepath.Clean(d.Root)))
+	}
+
+	d.releaseFileHashes[filePath] = sum
+}
+
+// hashingReadCloser computes the hash of all of the bytes read from the wrapped reader and reports
+// the hash upon close if the wrapped reader was read to completion.
+type hashingReadCloser struct {
+	reader     io.ReadCloser
+	hash       hash.Hash
+	complete   bool
+	onComplete func(sum string)
+}
+
+func (h *hashingReadCloser) Read(p []byte) (int, error) {
+	n, err := h.reader.Read(p)
+	h.hash.Write(p[:n])
+	if err == io.EOF {
+		h.complete = true
+	}
+
+	return n, err
+}
+
+func (h *hashingReadCloser) Close() error {
+	if h.complete {
+		h.onComplete(hex.EncodeToString(h.hash.Sum(nil)))
+	}
+
+	return h.reader.Close()
+}
+
+// timingReadCloser calls onClose when the wrapped reader is closed.
+type timingReadCloser struct {
+	reader  io.ReadCloser
+	onClose func()
+}
+
+func (t *timingReadCloser) Read(p []byte) (int, error) {
+	return t.reader.Read(p)
+}
+
+func (t *timingReadCloser) Close() error {
+	err := t.reader.Close()
+	t.onClose()
+	return err
+}
+
+// gzipReadCloser decompresses the wrapped file and closes it along with the decompressor.
+type gzipReadCloser struct {
+	*gzip.Reader
+	file io.Closer
+}
+
+func (g *gzipReadCloser) Close() error {
+	gzipErr := g.Reader.Close()
+	if err := g.file.Close(); err != nil {
+		return err
+	}
+
+	return gzipErr
+}
+
+// contextReadCloser aborts reads from the wrapped reader as soon as its context is done, even when
+// the wrapped reader is blocked (eg on a slow network mount).
+type contextReadCloser struct {
+	ctx    context.Context
+	reader io.ReadCloser
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+func (c *contextReadCloser) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	// Read into a separate buffer so that an abandoned read can't write into p after we return
+	buf := make([]byte, len(p))
+	results := make(chan readResult, 1)
+	go func() {
+		n, err := c.reader.Read(buf)
+		results <- readResult{n: n, err: err}
+	}()
+
+	select {
+	case <-c.ctx.Done():
+		return 0, c.ctx.Err()
+	case result := <-results:
+		copy(p, buf[:result.n])
+		return result.n, result.err
+	}
+}
+
+func (c *contextReadCloser) Close() error {
+	return c.reader.Close()
+}
@@ -0,0 +1,6 @@
+package linux
+
+// discover detects the distro by reading the release files under the detector's root.
+func (d *Detector) discover() (LinuxDistro, error) {
+	return d.discoverFromReleaseFiles()
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package linux
+
+// discover only reads the release files under an fs.FS or an explicit root, such as a mounted Linux
+// image. The host filesystem isn't a Linux distro on other platforms, so detecting it always reports an
+// unknown distro so that programs using this package can be built for every platform.
+func (d *Detector) discover() (LinuxDistro, error) {
+	if d.inspectsHostRoot() {
+		d.guessed = true
+
+		return unknownDistro(), nil
+	}
+
+	return d.discoverFromReleaseFiles()
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+// +build !linux
+
+package linux
+
+import "testing"
+
+func TestDiscoverDistroIsUnknownOnOtherPlatforms(t *testing.T) {
+	distro := NewDetector().DiscoverDistro()
+	if distro.ID != "unknown" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (unknown) was (%s).", distro.ID)
+	}
+	if distro.Name != "Unknown" {
+		t.Errorf("Linux distro name was not detected correctly. Expected (Unknown) was (%s).", distro.Name)
+	}
+	if distro.DetectedAt.IsZero() {
+		t.Error("detection time was not set for the unknown distro")
+	}
+}
+
+func TestDiscoverDistroWithRootOnOtherPlatforms(t *testing.T) {
+	// The root uses the separators (and drive letter) of the host OS
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Fedora Linux\"\nID=fedora\nVERSION_ID=39\n")
+
+	distro := WithRoot(root).DiscoverDistro()
+	if distro.ID != "fedora" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (fedora) was (%s).", distro.ID)
+	}
+	if distro.Version != "39" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (39) was (%s).", distro.Version)
+	}
+}
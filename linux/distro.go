@@ -2,6 +2,8 @@ package linux
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/dekobon/distro-detect/env"
@@ -9,27 +11,54 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
-	"path"
 	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 // Many thanks to the people who put together this data set: https://gist.github.com/natefoo/814c5bf936922dad97ff
 
 const moduleName = "github.com/dekobon/distro-detect"
 
+// Version is the version of distro-detect reported in LinuxDistro.DetectorVersion. It is set at build
+// time with: go build -ldflags "-X github.com/dekobon/distro-detect/linux.Version=x.y.z"
+var Version = "dev"
+
 var errorLog = log.New(os.Stderr, "error: ", 0)
 var warnLog = log.New(os.Stderr, "warn: ", 0)
+var debugLog = log.New(os.Stderr, "debug: ", 0)
 
+// FileSystemRoot is the root of the filesystem inspected by the package level Discover functions and
+// by detectors created with NewDetector.
+//
+// Deprecated: Create a detector for a specific root with WithRoot instead of changing this global.
 var FileSystemRoot = string(os.PathSeparator)
-var redhatCompatibleIds = []string{"centos", "fedora", "ol", "rhel", "scientific"}
-var rhelCompatibleIds = []string{"centos", "ol", "rhel", "scientific"}
+
+// redhatCompatibleIds and rhelCompatibleIds are the IDs of the distros in the Red Hat and RHEL families.
+// An ID_LIKE that contains any of these IDs places a distro in the family, so that derivatives of
+// derivatives (eg ID_LIKE="almalinux") are also in the family. The canonical IDs from CanonicalIDs (eg
+// oracle) are included so that normalized distros stay in their family.
+var redhatCompatibleIds = []string{"almalinux", "centos", "clearos", "fedora", "liberty", "nethserver", "ol", "oracle",
+	"rhel", "rocky", "scientific"}
+var rhelCompatibleIds = []string{"almalinux", "centos", "clearos", "liberty", "nethserver", "ol", "oracle", "rhel",
+	"rocky", "scientific"}
+
+// YoctoDistroIds are the os-release IDs of distros built with the Yocto Project / OpenEmbedded.
+// Append to this list to detect custom Yocto based distros.
+var YoctoDistroIds = []string{"poky"}
+
+// osReleasePaths are the locations of the os-release file in order of precedence. Systems with a
+// read-only /etc may only provide the file under /usr/lib or /run.
+var osReleasePaths = []string{"/etc/os-release", "/usr/lib/os-release", "/run/os-release"}
 
 var LogErrorf = func(format string, args ...interface{}) {
 	if len(args) > 0 {
-		errorLog.Printf(format, args)
+		errorLog.Printf(format, args...)
 	} else {
 		warnLog.Println(format)
 	}
@@ -37,17 +66,27 @@ var LogErrorf = func(format string, args ...interface{}) {
 
 var LogWarnf = func(format string, args ...interface{}) {
 	if len(args) > 0 {
-		warnLog.Printf(format, args)
+		warnLog.Printf(format, args...)
 	} else {
 		warnLog.Println(format)
 	}
 }
 
-var readBinaryFileFunc = func(filePaths []string) (io.ReadCloser, string, error) {
+var LogDebugf = func(format string, args ...interface{}) {
+	if len(args) > 0 {
+		debugLog.Printf(format, args...)
+	} else {
+		debugLog.Println(format)
+	}
+}
+
+var readBinaryFileFunc = func(d *Detector, filePaths []string) (io.ReadCloser, string, error) {
+	if d.fsys != nil {
+		return readBinaryFileFromFS(d.fsys, filePaths)
+	}
+
 	for _, filePath := range filePaths {
-		if FileSystemRoot != string(os.PathSeparator) {
-			filePath = path.Clean(FileSystemRoot + string(os.PathSeparator) + filePath)
-		}
+		filePath = d.rootedPath(filePath)
 
 		fileInfo, statErr := os.Stat(filePath)
 		if statErr != nil || fileInfo.IsDir() {
@@ -67,8 +106,8 @@ var readBinaryFileFunc = func(filePaths []string) (io.ReadCloser, string, error)
 	return nil, "", errors.New(errMsg)
 }
 
-var readFileFunc = func(filePaths ...string) (bool, string) {
-	reader, filePath, err := readBinaryFileFunc(filePaths)
+var readFileFunc = func(d *Detector, filePaths ...string) (bool, string) {
+	reader, filePath, err := d.readBinaryFile(filePaths...)
 	if err != nil {
 		return false, ""
 	}
@@ -84,8 +123,43 @@ var readFileFunc = func(filePaths ...string) (bool, string) {
 	return true, string(contents)
 }
 
+var readDirFunc = func(d *Detector, dirPath string) ([]string, error) {
+	fileInfos, err := d.readDirInfos(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(fileInfos))
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() {
+			names = append(names, fileInfo.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// readBinaryFileFromFS opens the first of the supplied paths that is a file in the supplied filesystem.
+func readBinaryFileFromFS(fsys fileSystem, filePaths []string) (io.ReadCloser, string, error) {
+	for _, filePath := range filePaths {
+		reader, fileInfo, openErr := fsys.open(filePath)
+		if openErr != nil {
+			continue
+		}
+		if fileInfo.IsDir() {
+			_ = reader.Close()
+			continue
+		}
+
+		return reader, filePath, nil
+	}
+
+	errMsg := fmt.Sprintf("unable to create a reader for any of the specified paths: %v", filePaths)
+	return nil, "", errors.New(errMsg)
+}
+
 // equalsSplitter is a regex to split apart key value pairs delimited with an equals sign
-var equalsSplitter = regexp.MustCompile("^\\s*(\\S+)\\s*=\\s*([\\S ]+)\\s*")
+var equalsSplitter = regexp.MustCompile("^\\s*([\\w.-]+)\\s*=\\s*([\\S ]+)\\s*")
 
 // releaseSplitter is a regex to split apart the contents of /etc/*-release files in the Red Hat Format
 var releaseSplitter = regexp.MustCompile("^(.+) (release|version)? (\\S+)\\s*(\\S+)?")
@@ -93,37 +167,138 @@ var releaseSplitter = regexp.MustCompile("^(.+) (release|version)? (\\S+)\\s*(\\
 type ReleaseDetails = map[string]string
 
 var DisplayKeys = map[string]string{
-	"name":        "Distro Name",
-	"id":          "Distro ID",
-	"version":     "Distro Version",
-	"lsb_release": "Distro LSB",
-	"os_release":  "Distro OS",
+	"name":                "Distro Name",
+	"id":                  "Distro ID",
+	"version":             "Distro Version",
+	"numeric_version":     "Distro Numeric Version",
+	"reported_version":    "Distro Reported Version",
+	"reported_id":         "Distro Reported ID",
+	"pretty_name":         "Distro Pretty Name",
+	"vendor":              "Distro Vendor",
+	"platform_id":         "Distro Platform ID",
+	"build_id":            "Distro Build ID",
+	"image_id":            "Distro Image ID",
+	"image_version":       "Distro Image Version",
+	"libc":                "Distro Libc",
+	"hardware_model":      "Distro Hardware Model",
+	"virtualization":      "Distro Virtualization",
+	"package_manager":     "Distro Package Manager",
+	"ubuntu_pro":          "Distro Ubuntu Pro",
+	"lts":                 "Distro LTS",
+	"edition":             "Distro Edition",
+	"detected_at":         "Distro Detected At",
+	"detector_version":    "Distro Detector Version",
+	"sdk_version":         "Distro SDK Version",
+	"lsb_release":         "Distro LSB",
+	"os_release":          "Distro OS",
+	"release_file_hashes": "Distro Release File SHA-256",
 }
 
+// requiredKeys are the keys that are always written by WriteAllResults. All other keys are only
+// written when they have a value.
+var requiredKeys = []string{"id", "name", "version", "lsb_release", "os_release"}
+
+// LinuxDistro is the distro detected on a filesystem. Fields are serialized to JSON in the order in
+// which they are declared, so ID (the stable key) is always serialized first.
 type LinuxDistro struct {
-	Name    string `json:"name"`
 	ID      string `json:"id"`
+	Name    string `json:"name"`
 	Version string `json:"version"`
+	// NumericVersion is the release number of a distro whose version is a codename (eg 13 for the
+	// trixie testing release of Debian) when it is known.
+	NumericVersion string `json:"numeric_version,omitempty"`
+	// ReportedVersion is the version as the distro reports it when the version was parsed out of a
+	// longer label (eg Grimoire 0.62-stable for Source Mage). It is empty otherwise.
+	ReportedVersion string `json:"reported_version,omitempty"`
+	// ReportedID is the ID claimed by os-release (or lsb-release) when it differs from the detected ID,
+	// such as when Oracle Linux reports itself as rhel. It is empty when the distro reports its own ID.
+	ReportedID string `json:"reported_id,omitempty"`
+	// PrettyName is the human readable name of the distro as the distro itself presents it.
+	PrettyName string `json:"pretty_name,omitempty"`
+	// Vendor is the company or project that publishes the distro (eg redhat or canonical) when known.
+	Vendor string `json:"vendor,omitempty"`
+	// BuildID identifies the build of the distro image (os-release BUILD_ID) when the distro provides it.
+	BuildID string `json:"build_id,omitempty"`
+	// ImageID identifies the image (os-release IMAGE_ID) that the system was deployed from, such as the
+	// image of an OSTree based system (eg Silverblue). It is empty for traditional distros.
+	ImageID string `json:"image_id,omitempty"`
+	// ImageVersion is the version of the image (os-release IMAGE_VERSION) identified by ImageID.
+	ImageVersion string `json:"image_version,omitempty"`
+	// SDKVersion is the SDK (API) level of the platform. It is only populated on Android.
+	SDKVersion string `json:"sdk_version,omitempty"`
+	// Libc is the C standard library used by the distro (musl or glibc) when it could be determined.
+	Libc string `json:"libc,omitempty"`
+	// HardwareModel is the board model reported by the device tree (eg on a Raspberry Pi).
+	HardwareModel string `json:"hardware_model,omitempty"`
+	// Virtualization is the hypervisor (eg kvm or vmware) identified by the DMI data. It is empty on
+	// bare metal or when the hypervisor isn't known.
+	Virtualization string `json:"virtualization,omitempty"`
+	// PackageManagerHint is the package manager found on disk when the distro couldn't be identified.
+	PackageManagerHint string `json:"package_manager_hint,omitempty"`
+	// UbuntuPro indicates that the distro is Ubuntu attached to an Ubuntu Pro (ESM) subscription.
+	UbuntuPro bool `json:"ubuntu_pro,omitempty"`
+	// IsLTS indicates that the release is a long term support release. It is only populated on Ubuntu.
+	IsLTS bool `json:"lts,omitempty"`
+	// Edition is the edition of the distro (server, desktop, cloud or minimal) when it could be determined.
+	Edition string `json:"edition,omitempty"`
+	// DetectedAt is the time (UTC) at which the distro was detected.
+	DetectedAt time.Time `json:"detected_at"`
+	// DetectorVersion is the version of distro-detect that detected the distro.
+	DetectorVersion string `json:"detector_version,omitempty"`
 	// LsbRelease contains the contents of /etc/lsb-release.
 	LsbRelease ReleaseDetails `json:"lsb_release"`
-	// OsRelease contains the contents of /etc/os-release. See: https://www.freedesktop.org/software/systemd/man/os-release.html
+	// OsRelease contains the contents of os-release (/etc/os-release, /usr/lib/os-release or /run/os-release). See: https://www.freedesktop.org/software/systemd/man/os-release.html
 	OsRelease ReleaseDetails `json:"os_release"`
+	// ReleaseFileHashes contains the SHA-256 hashes of the files read during detection keyed by path.
+	// It is only populated when Detector.HashReleaseFiles is enabled.
+	ReleaseFileHashes map[string]string `json:"release_file_hashes,omitempty"`
+	// ostreeBooted is set when OSTree markers were found under the detector's root during detection.
+	ostreeBooted bool
 }
 
 func (l *LinuxDistro) AsMap() map[string]interface{} {
 	return map[string]interface{}{
-		"name":        l.Name,
-		"id":          l.ID,
-		"version":     l.Version,
-		"lsb_release": l.LsbRelease,
-		"os_release":  l.OsRelease,
+		"name":                l.Name,
+		"id":                  l.ID,
+		"version":             l.Version,
+		"numeric_version":     l.NumericVersion,
+		"reported_version":    l.ReportedVersion,
+		"reported_id":         l.ReportedID,
+		"pretty_name":         l.PrettyName,
+		"vendor":              l.Vendor,
+		"platform_id":         l.PlatformID(),
+		"build_id":            l.BuildID,
+		"image_id":            l.ImageID,
+		"image_version":       l.ImageVersion,
+		"libc":                l.Libc,
+		"hardware_model":      l.HardwareModel,
+		"virtualization":      l.Virtualization,
+		"package_manager":     l.PackageManager(),
+		"ubuntu_pro":          l.UbuntuPro,
+		"lts":                 l.IsLTS,
+		"edition":             l.Edition,
+		"detected_at":         l.detectedAt(),
+		"detector_version":    l.DetectorVersion,
+		"sdk_version":         l.SDKVersion,
+		"lsb_release":         l.LsbRelease,
+		"os_release":          l.OsRelease,
+		"release_file_hashes": l.ReleaseFileHashes,
 	}
 }
 
 func (l *LinuxDistro) WriteAllResults(labelFormat string, writer io.Writer) error {
-	orderedKeys := []string{"id", "name", "version", "lsb_release", "os_release"}
+	// detected_at and detector_version are omitted so that the output of repeated runs is identical
+	orderedKeys := []string{"id", "name", "version", "numeric_version", "reported_version", "reported_id",
+		"pretty_name", "vendor", "platform_id", "build_id", "image_id", "image_version", "libc", "hardware_model",
+		"virtualization", "package_manager", "ubuntu_pro", "lts", "edition", "sdk_version", "lsb_release",
+		"os_release", "release_file_hashes"}
+	values := l.AsMap()
 
 	for _, key := range orderedKeys {
+		if isEmptyValue(values[key]) && !isRequiredKey(key) {
+			continue
+		}
+
 		err := l.WriteResult(labelFormat, key, writer)
 		if err != nil {
 			return err
@@ -147,6 +322,15 @@ func (l *LinuxDistro) WriteResult(labelFormat string, key string, writer io.Writ
 		if err != nil {
 			return err
 		}
+	case bool:
+		label := ""
+		if labelFormat != "" {
+			label = fmt.Sprintf(labelFormat, displayKey)
+		}
+		_, err := fmt.Fprintf(writer, "%s%t%s", label, value, env.LineBreak)
+		if err != nil {
+			return err
+		}
 	case ReleaseDetails:
 		ref := reflect.ValueOf(value)
 		detailsMap := ref.MapRange()
@@ -174,75 +358,405 @@ func (l *LinuxDistro) WriteResult(labelFormat string, key string, writer io.Writ
 	return nil
 }
 
-func (l *LinuxDistro) IsRedhatCompatible() bool {
-	for _, id := range redhatCompatibleIds {
-		if l.ID == id {
-			return true
+// Result returns the value of the supplied key (see DisplayKeys) formatted as a string and whether
+// the key is known. Properties such as os_release are formatted as KEY=VALUE lines sorted by key.
+func (l *LinuxDistro) Result(key string) (string, bool) {
+	value, ok := l.AsMap()[key]
+	if !ok {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case ReleaseDetails:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		lines := make([]string, len(keys))
+		for i, k := range keys {
+			lines[i] = k + "=" + v[k]
 		}
+
+		return strings.Join(lines, "\n"), true
 	}
 
-	if len(l.OsRelease["ID_LIKE"]) > 0 {
-		for _, id := range strings.Split(l.OsRelease["ID_LIKE"], " ") {
-			if id == "rhel" || id == "fedora" {
-				return true
-			}
+	return fmt.Sprintf("%v", value), true
+}
+
+// detectedAt returns DetectedAt formatted as RFC 3339 or an empty string when it isn't set.
+func (l *LinuxDistro) detectedAt() string {
+	if l.DetectedAt.IsZero() {
+		return ""
+	}
+
+	return l.DetectedAt.Format(time.RFC3339)
+}
+
+// WriteJSON writes the distro as JSON followed by a line break to the supplied writer. When indent is
+// true, the JSON is written over multiple indented lines.
+func (l *LinuxDistro) WriteJSON(writer io.Writer, indent bool) error {
+	var jsonOutput []byte
+	var err error
+
+	if indent {
+		jsonOutput, err = json.MarshalIndent(l, "", "  ")
+	} else {
+		jsonOutput, err = json.Marshal(l)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(writer, "%s%s", jsonOutput, env.LineBreak)
+	return err
+}
+
+// NormalizedVersion returns the version with a single leading "v" removed when it precedes a digit
+// (eg RancherOS reports "v1.5.6"). Version itself is left as reported by the distro so that existing
+// consumers of the raw value are not affected.
+func (l *LinuxDistro) NormalizedVersion() string {
+	if len(l.Version) > 1 && (l.Version[0] == 'v' || l.Version[0] == 'V') && unicode.IsDigit(rune(l.Version[1])) {
+		return l.Version[1:]
+	}
+
+	return l.Version
+}
+
+// PlatformID returns the PLATFORM_ID from os-release (eg "platform:el9"), which is shared by all of the
+// distros built from the same Enterprise Linux release.
+func (l *LinuxDistro) PlatformID() string {
+	return l.OsRelease["PLATFORM_ID"]
+}
+
+// lsbToOsReleaseKeys maps the lsb-release keys that have an os-release equivalent to that equivalent.
+var lsbToOsReleaseKeys = map[string]string{
+	"DISTRIB_ID":       "ID",
+	"DISTRIB_RELEASE":  "VERSION_ID",
+	"DISTRIB_CODENAME": "VERSION_CODENAME",
+}
+
+// MergedProperties returns the lsb-release and os-release properties combined into a single map. The
+// os-release properties take precedence and the lsb-release properties fill in any gaps. The lsb-release
+// keys DISTRIB_ID, DISTRIB_RELEASE and DISTRIB_CODENAME are renamed to their os-release equivalents ID,
+// VERSION_ID and VERSION_CODENAME. All other lsb-release keys are kept as is.
+func (l *LinuxDistro) MergedProperties() ReleaseDetails {
+	merged := ReleaseDetails{}
+
+	for key, val := range l.LsbRelease {
+		if osReleaseKey, ok := lsbToOsReleaseKeys[key]; ok {
+			key = osReleaseKey
 		}
+		merged[key] = val
 	}
 
-	return false
+	for key, val := range l.OsRelease {
+		merged[key] = val
+	}
+
+	return merged
 }
 
-func (l *LinuxDistro) IsRHELCompatible() bool {
-	for _, id := range rhelCompatibleIds {
-		if l.ID == id {
-			return true
+// Codename returns the codename of the distro release (eg focal). It is taken from VERSION_CODENAME
+// in os-release or DISTRIB_CODENAME in lsb-release, falling back to the text in parentheses at the end
+// of the os-release VERSION (eg Maipo for "7.6 (Maipo)"). An empty string is returned when the
+// distro has no codename.
+func (l *LinuxDistro) Codename() string {
+	if codename := l.MergedProperties()["VERSION_CODENAME"]; codename != "" {
+		return codename
+	}
+
+	return extractParenthetical(l.OsRelease["VERSION"])
+}
+
+// extractParenthetical returns the text within the last pair of parentheses in the supplied string
+// (eg "Focal Fossa" for "20.04.1 LTS (Focal Fossa)") or an empty string when there are none.
+func extractParenthetical(s string) string {
+	end := strings.LastIndex(s, ")")
+	if end < 0 {
+		return ""
+	}
+	start := strings.LastIndex(s[:end], "(")
+	if start < 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(s[start+1 : end])
+}
+
+// reportedID returns the ID that the distro claims in os-release or lsb-release when it differs from the
+// detected ID.
+func (l *LinuxDistro) reportedID() string {
+	reported := osReleaseID(l.OsRelease)
+	if reported == "" {
+		reported = strings.ToLower(l.LsbRelease["DISTRIB_ID"])
+	}
+
+	if reported == l.ID {
+		return ""
+	}
+
+	return reported
+}
+
+// distroVendors maps distro IDs to the vendor that publishes the distro.
+var distroVendors = map[string]string{
+	"amzn":           "amazon",
+	"android":        "google",
+	"centos":         "redhat",
+	"chromeos":       "google",
+	"clear-linux-os": "intel",
+	"debian":         "debian",
+	"fedora":         "redhat",
+	"liberty":        "suse",
+	"ol":             "oracle",
+	"opensuse":       "suse",
+	"photon":         "vmware",
+	"raspbian":       "raspberrypi",
+	"rhel":           "redhat",
+	"sles":           "suse",
+	"ubuntu":         "canonical",
+}
+
+// distroNames maps distro IDs to the names used by the distro tests. BestGuess uses it to name a
+// distro that reports a known ID but wasn't matched by any of the tests.
+var distroNames = map[string]string{
+	"alpine":         "Alpine Linux",
+	"altlinux":       "ALT Starterkit",
+	"amzn":           "Amazon Linux",
+	"android":        "Android",
+	"arch":           "Arch Linux",
+	"busybox":        "BusyBox",
+	"centos":         "CentOS Linux",
+	"chromeos":       "Chrome OS",
+	"clear-linux-os": "Clear Linux OS",
+	"clearos":        "ClearOS",
+	"clonezilla":     "Clonezilla Live",
+	"crux":           "CRUX",
+	"debian":         "Debian GNU/Linux",
+	"fedora":         "Fedora",
+	"freespire":      "Freespire",
+	"gentoo":         "Gentoo",
+	"hyperbola":      "Hyperbola GNU/Linux-libre",
+	"kali":           "Kali GNU/Linux",
+	"liberty":        "SUSE Liberty Linux",
+	"linuxmint":      "Linux Mint",
+	"mageia":         "Mageia",
+	"mandriva":       "Mandriva Linux",
+	"mx":             "MX Linux",
+	"nethserver":     "NethServer",
+	"nixos":          "NixOS",
+	"oes":            "Novell Open Enterprise Server",
+	"ol":             "Oracle Linux",
+	"opensuse":       "openSUSE",
+	"parabola":       "Parabola GNU/Linux-libre",
+	"photon":         "VMware Photon",
+	"puppy":          "Puppy Linux",
+	"rancheros":      "RancherOS",
+	"raspbian":       "Raspberry Pi OS",
+	"rhel":           "Red Hat Enterprise Linux",
+	"salix":          "Salix OS",
+	"scientific":     "Scientific Linux",
+	"slackware":      "Slackware",
+	"sles":           "SUSE Linux",
+	"sourcemage":     "Source Mage GNU/Linux",
+	"systemrescue":   "SystemRescue",
+	"tuxedo":         "TUXEDO OS",
+	"ubuntu":         "Ubuntu",
+	"yellow-dog":     "Yellow Dog Linux",
+	"zenwalk":        "Zenwalk",
+}
+
+// vendor returns the vendor of the distro based on its ID, falling back to the IDs in ID_LIKE.
+func (l *LinuxDistro) vendor() string {
+	if vendor, ok := distroVendors[l.ID]; ok {
+		return vendor
+	}
+
+	for _, likeId := range strings.Fields(l.OsRelease["ID_LIKE"]) {
+		if vendor, ok := distroVendors[likeId]; ok {
+			return vendor
 		}
 	}
 
-	if len(l.OsRelease["ID_LIKE"]) > 0 {
-		for _, id := range strings.Split(l.OsRelease["ID_LIKE"], " ") {
-			if id == "rhel" {
-				return true
-			}
+	return ""
+}
+
+// prettyName returns the PRETTY_NAME from os-release, falling back to DISTRIB_DESCRIPTION from
+// lsb-release and finally to the distro name and version.
+func (l *LinuxDistro) prettyName() string {
+	if l.OsRelease["PRETTY_NAME"] != "" {
+		return l.OsRelease["PRETTY_NAME"]
+	}
+	if l.LsbRelease["DISTRIB_DESCRIPTION"] != "" {
+		return l.LsbRelease["DISTRIB_DESCRIPTION"]
+	}
+
+	return strings.TrimSpace(l.Name + " " + l.Version)
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case ReleaseDetails:
+		return len(v) == 0
+	}
+
+	return value == nil
+}
+
+func isRequiredKey(key string) bool {
+	for _, requiredKey := range requiredKeys {
+		if key == requiredKey {
+			return true
 		}
 	}
 
 	return false
 }
 
+func (l *LinuxDistro) IsRedhatCompatible() bool {
+	return l.isLike(redhatCompatibleIds...)
+}
+
+func (l *LinuxDistro) IsRHELCompatible() bool {
+	return l.isLike(rhelCompatibleIds...)
+}
+
 func (l *LinuxDistro) UsesRPM() bool {
 	if l.IsRedhatCompatible() {
 		return true
 	}
 
-	if l.ID == "opensuse" || l.ID == "sles" {
+	distroId := aliasedID(l.ID)
+	if distroId == "opensuse" || distroId == "sles" || distroId == "mandriva" {
 		return true
 	}
 
 	return false
 }
 
+// PackageManager returns the package manager used by the distro (eg rpm, dpkg, apk or pacman). When the
+// package manager isn't known for the distro, the package manager found on disk is returned instead.
+func (l *LinuxDistro) PackageManager() string {
+	if l.UsesRPM() {
+		return "rpm"
+	}
+	if l.isLike("debian", "ubuntu", "linuxmint", "mx", "kali", "tuxedo", "freespire", "clonezilla") {
+		return "dpkg"
+	}
+	if l.isLike("alpine") {
+		return "apk"
+	}
+	if l.isLike("arch", "parabola", "hyperbola") {
+		return "pacman"
+	}
+
+	return l.PackageManagerHint
+}
+
+// isLike returns true when the distro ID or any of the IDs in ID_LIKE is one of the supplied IDs.
+func (l *LinuxDistro) isLike(ids ...string) bool {
+	for _, likeId := range l.likeIDs() {
+		for _, id := range ids {
+			if likeId == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// immutableDistroIds are the IDs of distros whose root filesystem is read-only and updated atomically.
+var immutableDistroIds = []string{"bottlerocket", "endless", "flatcar", "guix", "nixos", "opensuse-microos",
+	"rhcos", "steamos"}
+
+// immutableVariantIds are the VARIANT_IDs of the atomic editions of otherwise mutable distros (eg Fedora).
+var immutableVariantIds = []string{"coreos", "iot", "kinoite", "onyx", "sericea", "silverblue"}
+
+// ostreeMarkerPaths are the paths that are present when the system is booted from an OSTree deployment.
+var ostreeMarkerPaths = []string{"/run/ostree-booted", "/ostree"}
+
+// detectOSTreeBooted returns true when any of the OSTree markers is present under the detector's root.
+func (d *Detector) detectOSTreeBooted() bool {
+	for _, markerPath := range ostreeMarkerPaths {
+		if _, err := d.stat(markerPath); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsImmutable returns true when the distro has an immutable (atomic) root filesystem that can't be
+// modified by traditional package management. This is determined by the distro ID and VARIANT_ID, or by
+// the presence of OSTree markers under the root in which the distro was detected.
+func (l *LinuxDistro) IsImmutable() bool {
+	for _, id := range immutableDistroIds {
+		if l.ID == id {
+			return true
+		}
+	}
+
+	variantId := strings.ToLower(l.OsRelease["VARIANT_ID"])
+	for _, id := range immutableVariantIds {
+		if variantId == id {
+			return true
+		}
+	}
+
+	return l.ostreeBooted
+}
+
+// DistroTests lists the exported distro tests in the order in which a Detector runs their detector aware
+// forms. Changing it has no effect on detection.
 var DistroTests = []func(ReleaseDetails, ReleaseDetails) (bool, LinuxDistro){
+	IsOracleLinux, // Oracle Linux impersonates Red Hat, so it is ruled out once before the Red Hat family
+	IsNethServer,
+	IsClearOS,
 	IsCentOS,
+	IsLibertyLinux,
 	IsRHEL,
+	IsChromeOS,
+	IsTuxedoOS,
 	IsUbuntu,
+	IsClonezilla,
+	IsFreespire,
+	IsRaspberryPiOS,
+	IsMXLinux,
 	IsDebian,
 	IsAmazonLinux,
 	IsFedora,
 	IsOpenSuSE,
 	IsSLES,
-	IsOracleLinux,
 	IsPhoton,
 	IsAlpine,
+	IsSystemRescue,
+	IsParabola,
+	IsHyperbola,
 	IsArchLinux,
+
 	IsGentoo,
 	IsKali,
 	IsScientificLinux,
+	IsSalix,
+	IsZenwalk,
 	IsSlackware,
+	IsSerpentOS,
 	IsMageia,
+	IsMandriva,
 	IsClearLinux,
 	IsMint,
-	IsMXLinux,
 	IsNovellOES,
 	IsPuppy,
 	IsRancherOS,
@@ -252,6 +766,7 @@ var DistroTests = []func(ReleaseDetails, ReleaseDetails) (bool, LinuxDistro){
 	IsSourceMage,
 	IsAndroid,
 	IsYellowDog,
+	IsYocto,
 	IsBusyBox, // BusyBox should come last because it uses process execution
 }
 
@@ -273,33 +788,39 @@ func getFunctionName(i interface{}) string {
 }
 
 func DiscoverDistro() LinuxDistro {
-	lsbProperties, _ := readReleaseFile("/etc/lsb-release")
-	osReleaseProperties, _ := readReleaseFile("/etc/os-release")
-
-	return discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	return NewDetector().DiscoverDistro()
 }
 
-func discoverDistroFromProperties(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) LinuxDistro {
-	var detectedDistro LinuxDistro
-	wasDetected := false
-
-	for _, distroTest := range DistroTests {
-		wasDetected, detectedDistro = distroTest(lsbProperties, osReleaseProperties)
+// DetectedPackageManager returns the package manager whose binary is installed in the filesystem at
+// FileSystemRoot.
+func DetectedPackageManager() string {
+	return NewDetector().DetectedPackageManager()
+}
 
-		if wasDetected {
-			break
-		}
-	}
+// DiscoverDistroE detects the distro of the filesystem at FileSystemRoot and returns it along with
+// the warnings gathered during detection.
+func DiscoverDistroE() (DetectionResult, error) {
+	return NewDetector().DiscoverDistroE()
+}
 
-	if !wasDetected {
-		detectedDistro = BestGuess(lsbProperties, osReleaseProperties)
-	}
+// DiscoverDistroContext detects the distro of the filesystem at FileSystemRoot, aborting any pending
+// file reads when the supplied context is done.
+func DiscoverDistroContext(ctx context.Context) (LinuxDistro, error) {
+	return NewDetector().DiscoverDistroContext(ctx)
+}
 
-	return detectedDistro
+// DetectAll returns every distro in DistroTests that matches the supplied properties rather than
+// only the first match.
+func DetectAll(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) []LinuxDistro {
+	return NewDetector().DetectAll(lsbProperties, osReleaseProperties)
 }
 
 func BestGuess(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) LinuxDistro {
-	LogWarnf("distro is not part of the existing data set - attempting best guess")
+	return bestGuess(NewDetector(), lsbProperties, osReleaseProperties)
+}
+
+func bestGuess(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) LinuxDistro {
+	d.warnf("distro is not part of the existing data set - attempting best guess")
 
 	var id string
 	if osReleaseProperties["ID"] != "" {
@@ -318,6 +839,10 @@ func BestGuess(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 		name = segments[0]
 	} else if lsbProperties["DISTRIB_ID"] != "" {
 		name = lsbProperties["DISTRIB_ID"]
+	} else if lsbProperties["DISTRIB_DESCRIPTION"] != "" {
+		name = lsbProperties["DISTRIB_DESCRIPTION"]
+	} else if knownName, ok := distroNames[osReleaseID(osReleaseProperties)]; ok {
+		name = knownName
 	} else if osReleaseProperties["ID"] != "" {
 		name = osReleaseProperties["ID"]
 	} else {
@@ -336,27 +861,110 @@ func BestGuess(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 		version = "unknown"
 	}
 
+	// Repackaged Red Hat family images may replace the ID with a generic value
+	if id == "unknown" || id == "linux" {
+		if matched, productId, productName := guessFromRedhatSupportProduct(osReleaseProperties); matched {
+			id = productId
+			name = productName
+		}
+	}
+
+	// Novel Red Hat family distros may only be identifiable from their release file
+	if id == "unknown" {
+		exists, contents := d.readNonEmptyFile("/etc/redhat-release")
+		if exists {
+			if matched, releaseId, releaseName, releaseVersion := parseUnknownRedhatReleaseContents(contents); matched {
+				id = releaseId
+				name = releaseName
+				if version == "unknown" {
+					version = releaseVersion
+				}
+			}
+		}
+	}
+
+	// When there are no release files at all, the package repositories or the kernel build string may
+	// still identify the distro
+	if id == "unknown" {
+		if matched, repoId, repoName := guessFromYumRepos(d); matched {
+			id = repoId
+			name = repoName
+		}
+	}
+	if id == "unknown" {
+		if matched, kernelId, kernelName := guessFromKernelVersion(d); matched {
+			id = kernelId
+			name = kernelName
+		}
+	}
+	if id == "unknown" {
+		if matched, efiId, efiName := guessFromEFIVendor(d); matched {
+			id = efiId
+			name = efiName
+		}
+	}
+	if name == "Unknown" {
+		if matched, releaseName := guessFromSolarisRelease(d); matched {
+			name = releaseName
+		}
+	}
+
 	return LinuxDistro{
-		Name:       name,
-		ID:         id,
-		Version:    version,
-		LsbRelease: lsbProperties,
-		OsRelease:  osReleaseProperties,
+		Name:               name,
+		ID:                 id,
+		Version:            version,
+		PackageManagerHint: detectPackageManagerFromFS(d),
+		LsbRelease:         lsbProperties,
+		OsRelease:          osReleaseProperties,
 	}
 }
 
-func readReleaseFile(filePath string) (ReleaseDetails, error) {
-	reader, pathRead, openErr := readBinaryFileFunc([]string{filePath})
+// osReleaseID returns the os-release ID in lower case because some images report it in upper case (eg OL).
+func osReleaseID(osReleaseProperties ReleaseDetails) string {
+	return strings.ToLower(osReleaseProperties["ID"])
+}
+
+// withSynthesizedID returns the os-release properties with an ID derived from NAME (eg "Fedora" becomes
+// "fedora") when ID is missing, as it is for early adopters of os-release. The supplied properties are
+// returned unmodified when there is already an ID or there is no NAME to derive it from.
+func withSynthesizedID(osReleaseProperties ReleaseDetails) ReleaseDetails {
+	if osReleaseProperties["ID"] != "" {
+		return osReleaseProperties
+	}
+
+	nameSegments := strings.Fields(osReleaseProperties["NAME"])
+	if len(nameSegments) == 0 {
+		return osReleaseProperties
+	}
+
+	synthesized := make(ReleaseDetails, len(osReleaseProperties)+1)
+	for key, val := range osReleaseProperties {
+		synthesized[key] = val
+	}
+	synthesized["ID"] = strings.ToLower(nameSegments[0])
+
+	return synthesized
+}
+
+// readReleaseFile parses the first of the supplied release files that exists.
+func readReleaseFile(d *Detector, filePaths ...string) (ReleaseDetails, error) {
+	reader, pathRead, openErr := d.readBinaryFile(filePaths...)
 	if openErr != nil {
-		if pathRead != "" {
-			warnLog.Printf("unable to read release file at the path: %s", pathRead)
+		// A release file that doesn't exist isn't an error, it is just a different distro
+		if pathRead == "" {
+			return ReleaseDetails{}, nil
 		}
 
+		d.warnf("unable to read release file at the path: %s", pathRead)
 		return ReleaseDetails{}, openErr
 	}
 	defer func() { _ = reader.Close() }()
 
 	properties, parseErr := parseOSRelease(reader)
+	if parseErr != nil {
+		d.warnf("unable to parse release file (%s): %v", pathRead, parseErr)
+	}
+
 	return properties, parseErr
 }
 
@@ -386,6 +994,12 @@ func splitEqualsKeyVal(line string) (string, string, error) {
 		return "", "", errors.New(fmt.Sprintf("ignoring commented line: %s", line))
 	}
 
+	// Files that are meant to be sourced by a shell may export each of the variables
+	line = strings.TrimLeft(line, " \t")
+	if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+		line = line[len("export "):]
+	}
+
 	match := equalsSplitter.FindStringSubmatch(line)
 	if len(match) == 0 {
 		return "", "", errors.New(fmt.Sprintf("no splittable character for line: %s", line))
@@ -396,12 +1010,20 @@ func splitEqualsKeyVal(line string) (string, string, error) {
 
 	withoutTrailingWhitespace := strings.TrimSpace(match[2])
 	withoutEnclosingQuotes := strings.Trim(withoutTrailingWhitespace, "\"")
+	// Values may also be enclosed in single quotes
+	if len(withoutEnclosingQuotes) > 1 && strings.HasPrefix(withoutEnclosingQuotes, "'") &&
+		strings.HasSuffix(withoutEnclosingQuotes, "'") {
+		withoutEnclosingQuotes = withoutEnclosingQuotes[1 : len(withoutEnclosingQuotes)-1]
+	}
 
 	return match[1], withoutEnclosingQuotes, nil
 }
 
 func parseRedhatReleaseContents(contents string, expectedDistro string) (bool, string) {
 	matches := releaseSplitter.FindStringSubmatch(contents)
+	if len(matches) == 0 {
+		return false, ""
+	}
 
 	if !strings.HasPrefix(matches[0], expectedDistro) {
 		return false, ""
@@ -417,3 +1039,42 @@ func parseRedhatReleaseContents(contents string, expectedDistro string) (bool, s
 
 	return true, version
 }
+
+// parseUnknownRedhatReleaseContents parses the contents of a release file in the Red Hat format (eg
+// "Frobozz Linux release 2.3 (Zork)") for a distro that isn't known. The name is everything before
+// "release" or "version", and the ID is the first word of the name in lower case.
+func parseUnknownRedhatReleaseContents(contents string) (bool, string, string, string) {
+	matches := releaseSplitter.FindStringSubmatch(contents)
+	if len(matches) < 4 || matches[2] == "" {
+		return false, "", "", ""
+	}
+
+	name := strings.TrimSpace(matches[1])
+	nameFields := strings.Fields(name)
+	if len(nameFields) == 0 {
+		return false, "", "", ""
+	}
+
+	return true, strings.ToLower(nameFields[0]), name, strings.TrimSpace(matches[3])
+}
+
+// platformVersion cross-checks a version parsed from a release file against the major version in the
+// os-release PLATFORM_ID (eg "platform:el9"). The major version from the platform is returned when the
+// parsed version is unknown or disagrees with it.
+func platformVersion(version string, osReleaseProperties ReleaseDetails) string {
+	platformId := osReleaseProperties["PLATFORM_ID"]
+	if !strings.HasPrefix(platformId, "platform:el") {
+		return version
+	}
+
+	major := strings.TrimPrefix(platformId, "platform:el")
+	if major == "" || strings.IndexFunc(major, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0 {
+		return version
+	}
+
+	if strings.SplitN(version, ".", 2)[0] == major {
+		return version
+	}
+
+	return major
+}
@@ -1,12 +1,21 @@
 package linux
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/dekobon/distro-detect/env"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -32,9 +41,9 @@ func TestMain(m *testing.M) {
 	var seed int64 = time.Now().UnixNano()
 	fmt.Printf("DistroTest random seed: %d%s", seed, env.LineBreak)
 	rand.Seed(seed)
-	rand.Shuffle(len(DistroTests), func(i, j int) { DistroTests[i], DistroTests[j] = DistroTests[j], DistroTests[i] })
-	distroTestNames := DistroTestFunctionsToFunctionNames(DistroTests)
-	fmt.Printf("DistroTest order: %v%s", strings.Join(distroTestNames, " "), env.LineBreak)
+	unshuffledDistroTestNames = distroTestNames(distroTestTable)
+	shuffleDistroTests(distroTestTable)
+	fmt.Printf("DistroTest order: %v%s", strings.Join(distroTestNames(distroTestTable), " "), env.LineBreak)
 
 	// Store original read file function and restore it after test run has completed
 	origReadFileFunc := readFileFunc
@@ -43,13 +52,63 @@ func TestMain(m *testing.M) {
 	}()
 	// Replace read file function with a function that always returns "not found". If a test needs
 	// to use the function, it will be responsible for overriding it.
-	readFileFunc = func(...string) (bool, string) {
+	readFileFunc = func(*Detector, ...string) (bool, string) {
 		return false, ""
 	}
 	m.Run()
 
 }
 
+// unshuffledDistroTestNames holds the order of distroTestTable before TestMain shuffled it.
+var unshuffledDistroTestNames []string
+
+func distroTestNames(distroTests []distroTest) []string {
+	names := make([]string, len(distroTests))
+	for i, test := range distroTests {
+		names[i] = test.name
+	}
+
+	return names
+}
+
+// shuffleDistroTests shuffles the distro tests while keeping every test after the tests that precede it.
+func shuffleDistroTests(distroTests []distroTest) {
+	levels := map[string]int{}
+	var level func(test distroTest) int
+	level = func(test distroTest) int {
+		if l, ok := levels[test.name]; ok {
+			return l
+		}
+		l := 0
+		for _, name := range test.precededBy {
+			if precedingLevel := level(distroTestsByName[name]) + 1; precedingLevel > l {
+				l = precedingLevel
+			}
+		}
+		levels[test.name] = l
+		return l
+	}
+
+	rand.Shuffle(len(distroTests), func(i, j int) { distroTests[i], distroTests[j] = distroTests[j], distroTests[i] })
+	sort.SliceStable(distroTests, func(i, j int) bool { return level(distroTests[i]) < level(distroTests[j]) })
+}
+
+func TestDistroTestsMatchDistroTestTable(t *testing.T) {
+	names := DistroTestFunctionsToFunctionNames(DistroTests)
+	if !reflect.DeepEqual(names, unshuffledDistroTestNames) {
+		t.Errorf("DistroTests is not in the order of distroTestTable. Expected (%v) was (%v).",
+			unshuffledDistroTestNames, names)
+	}
+
+	for _, test := range distroTestTable {
+		for _, name := range test.precededBy {
+			if _, ok := distroTestsByName[name]; !ok {
+				t.Errorf("the test (%s) is preceded by an unknown test (%s)", test.name, name)
+			}
+		}
+	}
+}
+
 func TestParseEmptyOSRelease(t *testing.T) {
 	data := ""
 	reader := strings.NewReader(data)
@@ -123,6 +182,48 @@ func TestSplitEqualsKeyValWithWhitespace(t *testing.T) {
 	}
 }
 
+func TestSplitEqualsKeyValWithEqualsInValue(t *testing.T) {
+	actual := "HOME_URL=\"https://example.com/?a=b\""
+	k, v, err := splitEqualsKeyVal(actual)
+	if err != nil {
+		t.Error(err)
+	}
+	if k != "HOME_URL" {
+		t.Errorf("k has unexpected value: [%s]", k)
+	}
+	if v != "https://example.com/?a=b" {
+		t.Errorf("v has unexpected value: [%s]", v)
+	}
+}
+
+func TestSplitEqualsKeyValWithEnclosingSingleQuotes(t *testing.T) {
+	actual := "a_single_key='a single value'"
+	k, v, err := splitEqualsKeyVal(actual)
+	if err != nil {
+		t.Error(err)
+	}
+	if k != "a_single_key" {
+		t.Errorf("k has unexpected value: [%s]", k)
+	}
+	if v != "a single value" {
+		t.Errorf("v has unexpected value: [%s]", v)
+	}
+}
+
+func TestSplitEqualsKeyValWithExport(t *testing.T) {
+	actual := "export ID=ubuntu"
+	k, v, err := splitEqualsKeyVal(actual)
+	if err != nil {
+		t.Error(err)
+	}
+	if k != "ID" {
+		t.Errorf("k has unexpected value: [%s]", k)
+	}
+	if v != "ubuntu" {
+		t.Errorf("v has unexpected value: [%s]", v)
+	}
+}
+
 func TestParseMissingDelimiterOSRelease(t *testing.T) {
 	data := "SOMETHING-NO-SEPARATOR"
 	reader := strings.NewReader(data)
@@ -162,6 +263,69 @@ func TestParseUbuntuOSRelease(t *testing.T) {
 	}
 }
 
+func TestParseExportedOSRelease(t *testing.T) {
+	data := "export NAME=\"Ubuntu\"\nexport ID=ubuntu\nexport VERSION_ID=\"22.04\"\nexported=true\n"
+	reader := strings.NewReader(data)
+
+	properties, err := parseOSRelease(reader)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := map[string]string{
+		"NAME":       "Ubuntu",
+		"ID":         "ubuntu",
+		"VERSION_ID": "22.04",
+		"exported":   "true",
+	}
+
+	if !reflect.DeepEqual(properties, expected) {
+		t.Errorf("unexpected values parsed from os release data:\nExpected:\n%s\nActual:\n%s",
+			expected, properties)
+	}
+}
+
+func TestSplitEqualsKeyValUnquotedWithTrailingWhitespace(t *testing.T) {
+	lines := []string{
+		"ORACLE_BUGZILLA_PRODUCT_VERSION=6.10 ",
+		"ORACLE_BUGZILLA_PRODUCT_VERSION=6.10\t",
+		"ORACLE_BUGZILLA_PRODUCT_VERSION=6.10 \t ",
+		"ORACLE_BUGZILLA_PRODUCT_VERSION = 6.10\t\r",
+		"ORACLE_BUGZILLA_PRODUCT_VERSION\t=\t6.10\t",
+	}
+
+	for _, line := range lines {
+		k, v, err := splitEqualsKeyVal(line)
+		if err != nil {
+			t.Error(err)
+		}
+		if k != "ORACLE_BUGZILLA_PRODUCT_VERSION" {
+			t.Errorf("k has unexpected value for line (%q): [%s]", line, k)
+		}
+		if v != "6.10" {
+			t.Errorf("v has unexpected value for line (%q): [%s]", line, v)
+		}
+	}
+}
+
+func TestSplitEqualsKeyValQuotedWithTrailingWhitespace(t *testing.T) {
+	lines := []string{
+		"NAME=\"Oracle Linux Server\" ",
+		"NAME=\"Oracle Linux Server\"\t",
+		"NAME = \"Oracle Linux Server\" \t",
+	}
+
+	for _, line := range lines {
+		_, v, err := splitEqualsKeyVal(line)
+		if err != nil {
+			t.Error(err)
+		}
+		if v != "Oracle Linux Server" {
+			t.Errorf("v has unexpected value for line (%q): [%s]", line, v)
+		}
+	}
+}
+
 func TestOracleLinuxOSRelease(t *testing.T) {
 	data := "NAME=\"Oracle Linux Server\" \nVERSION=\"6.10\" \nID=\"ol\" \nVERSION_ID=\"6.10\" \nPRETTY_NAME=\"Oracle Linux Server 6.10\"\nANSI_COLOR=\"0;31\" \nCPE_NAME=\"cpe:/o:oracle:linux:6:10:server\"\nHOME_URL=\"https://linux.oracle.com/\" \nBUG_REPORT_URL=\"https://bugzilla.oracle.com/\" \n\nORACLE_BUGZILLA_PRODUCT=\"Oracle Linux 6\" \nORACLE_BUGZILLA_PRODUCT_VERSION=6.10 \nORACLE_SUPPORT_PRODUCT=\"Oracle Linux\" \nORACLE_SUPPORT_PRODUCT_VERSION=6.10\n"
 	reader := strings.NewReader(data)
@@ -193,6 +357,68 @@ func TestOracleLinuxOSRelease(t *testing.T) {
 	}
 }
 
+func TestOracleLinuxOSReleaseWithTrailingTabs(t *testing.T) {
+	data := "NAME=\"Oracle Linux Server\"\t\nVERSION=\"6.10\" \t\nID=\"ol\"\t\nVERSION_ID=\"6.10\"\t \nORACLE_BUGZILLA_PRODUCT_VERSION=6.10\t\nORACLE_SUPPORT_PRODUCT_VERSION=6.10 \t\n"
+	reader := strings.NewReader(data)
+
+	properties, err := parseOSRelease(reader)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := map[string]string{
+		"NAME":                            "Oracle Linux Server",
+		"VERSION":                         "6.10",
+		"ID":                              "ol",
+		"VERSION_ID":                      "6.10",
+		"ORACLE_BUGZILLA_PRODUCT_VERSION": "6.10",
+		"ORACLE_SUPPORT_PRODUCT_VERSION":  "6.10",
+	}
+
+	if !reflect.DeepEqual(properties, expected) {
+		t.Errorf("unexpected values parsed from os release data:\nExpected:\n%s\nActual:\n%s",
+			expected, properties)
+	}
+}
+
+func TestValidateOSRelease(t *testing.T) {
+	data := "ID=Acme\nID_LIKE=\"rhel Fedora\"\nVERSION_ID=\"1.0 beta\"\nBUILD_ID=\"2024 05\"\nHOME_URL=\"www.example.com\"\n"
+	properties, err := parseOSRelease(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"NAME is missing",
+		"ID must only contain lowercase letters, digits, \".\", \"_\" and \"-\": Acme",
+		"VERSION_ID must only contain lowercase letters, digits, \".\", \"_\" and \"-\": 1.0 beta",
+		"ID_LIKE must only contain IDs of lowercase letters, digits, \".\", \"_\" and \"-\": Fedora",
+		"BUILD_ID must not contain whitespace: 2024 05",
+		"HOME_URL must be a valid URL: www.example.com",
+	}
+	violations := ValidateOSRelease(properties)
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("unexpected violations:\nExpected:\n%s\nActual:\n%s", strings.Join(expected, "\n"),
+			strings.Join(violations, "\n"))
+	}
+}
+
+func TestValidateOSReleaseValid(t *testing.T) {
+	properties := ReleaseDetails{
+		"NAME":           "Fedora Linux",
+		"ID":             "fedora",
+		"VERSION_ID":     "39",
+		"PRETTY_NAME":    "Fedora Linux 39 (Container Image)",
+		"VARIANT_ID":     "container",
+		"HOME_URL":       "https://fedoraproject.org/",
+		"BUG_REPORT_URL": "https://bugzilla.redhat.com/",
+	}
+
+	if violations := ValidateOSRelease(properties); len(violations) != 0 {
+		t.Errorf("valid os-release properties had violations: %v", violations)
+	}
+}
+
 func TestParseRedhatReleaseContentsRHEL(t *testing.T) {
 	contents := "Red Hat Enterprise Linux Server release 7.6 (Maipo)\n"
 	expected := "7.6"
@@ -217,9 +443,28 @@ func TestParseRedhatReleaseContentsOldGentoo(t *testing.T) {
 	}
 }
 
+func TestDiscoverAerynOS(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":             "AerynOS",
+		"VERSION":          "2025.03",
+		"VERSION_ID":       "2025.03",
+		"VERSION_CODENAME": "Bloom",
+		"ID":               "aeryn",
+		"ANSI_COLOR":       "1;35",
+		"PRETTY_NAME":      "AerynOS 2025.03 (Bloom)",
+		"HOME_URL":         "https://aerynos.com",
+		"SUPPORT_URL":      "https://github.com/orgs/AerynOS/discussions",
+		"BUG_REPORT_URL":   "https://github.com/AerynOS",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "aeryn", "AerynOS", "2025.03", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverAlpineOld(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/alpine-release"}) {
 			return true, "3.12.1"
 		} else {
@@ -238,7 +483,7 @@ func TestDiscoverAlpineOld(t *testing.T) {
 
 func TestDiscoverAlpine3(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/alpine-release"}) {
 			return true, "3.12.1"
 		} else {
@@ -262,6 +507,40 @@ func TestDiscoverAlpine3(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverAlpineEdge(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/alpine-release"}) {
+			return true, "edge\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "alpine", "Alpine Linux", "edge", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverAlpineEdgeOSRelease(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":           "Alpine Linux",
+		"ID":             "alpine",
+		"VERSION_ID":     "3.19.0_alpha20231219",
+		"PRETTY_NAME":    "Alpine Linux edge",
+		"HOME_URL":       "https://alpinelinux.org/",
+		"BUG_REPORT_URL": "https://gitlab.alpinelinux.org/alpine/aports/-/issues",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "alpine", "Alpine Linux", "edge", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverAlt(t *testing.T) {
 	lsbProperties := map[string]string{}
 	osReleaseProperties := map[string]string{
@@ -298,9 +577,27 @@ func TestDiscoverAmazonLinux(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverAmazonLinux1(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":        "Amazon Linux AMI",
+		"VERSION":     "2018.03",
+		"ID":          "amzn",
+		"ID_LIKE":     "rhel fedora",
+		"VERSION_ID":  "2018.03",
+		"PRETTY_NAME": "Amazon Linux AMI 2018.03",
+		"ANSI_COLOR":  "0;33",
+		"CPE_NAME":    "cpe:/o:amazon:linux:2018.03:ga",
+		"HOME_URL":    "http://aws.amazon.com/amazon-linux-ami/",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "amzn", "Amazon Linux AMI 2018.03", "2018.03", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverAndroid(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/system/build.prop"}) {
 			return true, "\n# begin build properties\n# autogenerated by buildinfo.sh\nro.build.id=PI\nro.build.display.id=android_x86_64-userdebug 9 PI eng.lh.20200325.112926 test-keys\nro.build.version.incremental=eng.lh.20200325.112926\nro.build.version.sdk=28\nro.build.version.preview_sdk=0\nro.build.version.codename=REL\nro.build.version.all_codenames=REL\nro.build.version.release=9\nro.build.version.security_patch=2018-08-05\nro.build.version.base_os=\nro.build.version.min_supported_target_sdk=17\nro.build.date=Wed Mar 25 11:28:56 CST 2020\nro.build.date.utc=1585106936\nro.build.type=userdebug\nro.build.user=lh\nro.build.host=server2\nro.build.tags=test-keys\nro.build.flavor=android_x86_64-userdebug\nro.product.brand=Android-x86\nro.product.name=android_x86_64\nro.product.device=x86_64\n# ro.product.cpu.abi and ro.product.cpu.abi2 are obsolete,\n# use ro.product.cpu.abilist instead.\nro.product.cpu.abi=x86_64\nro.product.cpu.abilist=x86_64,x86,armeabi-v7a,armeabi\nro.product.cpu.abilist32=x86,armeabi-v7a,armeabi\nro.product.cpu.abilist64=x86_64\nro.product.locale=en-US\nro.wifi.channels=\n# ro.build.product is obsolete; use ro.product.device\nro.build.product=x86_64\n# Do not try to parse description, fingerprint, or thumbprint\nro.build.description=android_x86_64-userdebug 9 PI eng.lh.20200325.112926 test-keys\nro.build.fingerprint=Android-x86/android_x86_64/x86_64:9/PI/lh03251128:userdebug/test-keys\nro.build.characteristics=tablet\n# end build properties\n\n#\n# ADDITIONAL_BUILD_PROPERTIES\n#\nro.com.android.dateformat=MM-dd-yyyy\nro.ril.hsxpa=1\nro.ril.gprsclass=10\nkeyguard.no_require_sim=true\nro.com.android.dataroaming=true\nmedia.sf.hwaccel=1\nmedia.sf.omx-plugin=libffmpeg_omx.so\nmedia.sf.extractor-plugin=libffmpeg_extractor.so\nro.opengles.version=196608\nro.hardware.vulkan.level=1\nro.hardware.vulkan.version=4194307\ndalvik.vm.heapstartsize=16m\ndalvik.vm.heapgrowthlimit=192m\ndalvik.vm.heapsize=512m\ndalvik.vm.heaptargetutilization=0.75\ndalvik.vm.heapminfree=512k\ndalvik.vm.heapmaxfree=8m\nro.com.google.gmsversion=9.0_r1\nro.com.google.clientidbase=android-asus\nro.com.google.clientidbase.ms=android-asus\nro.com.google.clientidbase.am=android-asus\nro.com.google.clientidbase.gmm=android-asus\nro.com.google.clientidbase.yt=android-asus\nro.setupwizard.mode=ENABLED\nro.dalvik.vm.isa.arm=x86\nro.enable.native.bridge.exec=1\nro.dalvik.vm.isa.arm64=x86_64\nro.enable.native.bridge.exec64=1\nro.carrier=unknown\nro.config.notification_sound=OnTheHunt.ogg\nro.config.alarm_alert=Alarm_Classic.ogg\nro.dalvik.vm.native.bridge=0\nro.bionic.ld.warning=1\nro.art.hiddenapi.warning=1\nro.treble.enabled=false\npersist.sys.dalvik.vm.lib.2=libart.so\ndalvik.vm.isa.x86_64.variant=x86_64\ndalvik.vm.isa.x86_64.features=default\ndalvik.vm.isa.x86.variant=x86_64\ndalvik.vm.isa.x86.features=default\ndalvik.vm.lockprof.threshold=500\nnet.bt.name=Android\ndalvik.vm.stack-trace-dir=/data/anr\n"
 		} else {
@@ -314,7 +611,32 @@ func TestDiscoverAndroid(t *testing.T) {
 	lsbProperties := map[string]string{}
 	osReleaseProperties := map[string]string{}
 
-	distroIsDetectedBasedOnProperties(t, "android", "Android", "9.0_r1", lsbProperties,
+	distroIsDetectedBasedOnProperties(t, "android", "Android", "9", lsbProperties,
+		osReleaseProperties)
+
+	_, distro := isAndroid(NewDetector(), lsbProperties, osReleaseProperties)
+	if distro.SDKVersion != "28" {
+		t.Errorf("Android SDK version was not detected correctly. Expected (28) was (%s).", distro.SDKVersion)
+	}
+}
+
+func TestDiscoverAndroidAOSP(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/system/build.prop"}) {
+			return true, "\n# begin build properties\nro.build.id=PQ3A.190801.002\nro.build.version.sdk=28\nro.build.version.codename=REL\nro.build.version.release=9\nro.build.type=userdebug\nro.product.brand=Android\n# end build properties\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "android", "Android", "9", lsbProperties,
 		osReleaseProperties)
 }
 
@@ -333,13 +655,16 @@ func TestDiscoverArchLinux(t *testing.T) {
 		"LOGO":              "archlinux",
 	}
 
-	distroIsDetectedBasedOnProperties(t, "arch", "Arch Linux", "rolling", lsbProperties,
+	distro := distroIsDetectedBasedOnProperties(t, "arch", "Arch Linux", "rolling", lsbProperties,
 		osReleaseProperties)
+	if distro.BuildID != "rolling" {
+		t.Errorf("build id was not detected correctly. Expected (rolling) was (%s).", distro.BuildID)
+	}
 }
 
 func TestDiscoverBusyBox(t *testing.T) {
 	originalReadBinaryFileFunc := readBinaryFileFunc
-	readBinaryFileFunc = func(filePaths []string) (io.ReadCloser, string, error) {
+	readBinaryFileFunc = func(_ *Detector, filePaths []string) (io.ReadCloser, string, error) {
 		if reflect.DeepEqual(filePaths, []string{"/bin/true"}) {
 			reader, err := os.Open("test-binary-busybox-amd64-true")
 			return reader, "/bin/true", err
@@ -358,9 +683,42 @@ func TestDiscoverBusyBox(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverBusyBoxWithoutAllowExec(t *testing.T) {
+	busyBoxTrue, err := ioutil.ReadFile("test-binary-busybox-amd64-true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	writeTestFile(t, root, "/bin/true", string(busyBoxTrue))
+
+	detector := WithRoot(root)
+	detector.AllowExec = false
+	result, err := detector.DiscoverDistroE()
+	if err != nil && !errors.Is(err, ErrDistroNotDetected) {
+		t.Fatal(err)
+	}
+	if result.Distro.ID == "busybox" {
+		t.Error("BusyBox was detected when reading executables isn't allowed")
+	}
+
+	skipped := false
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning, "BusyBox detection was skipped") {
+			skipped = true
+		}
+	}
+	if !skipped {
+		t.Errorf("skipped BusyBox detection was not among the warnings: %v", result.Warnings)
+	}
+
+	if distro := WithRoot(root).DiscoverDistro(); distro.ID != "busybox" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (busybox) was (%s).", distro.ID)
+	}
+}
+
 func TestDiscoverCentOS5(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/centos-release", "/etc/redhat-release"}) {
 			return true, "CentOS release 5.11 (Final)\n"
 		} else {
@@ -380,7 +738,7 @@ func TestDiscoverCentOS5(t *testing.T) {
 
 func TestDiscoverCentOS6(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/centos-release", "/etc/redhat-release"}) {
 			return true, "CentOS release 6.10 (Final)\n"
 		} else {
@@ -400,7 +758,7 @@ func TestDiscoverCentOS6(t *testing.T) {
 
 func TestDiscoverCentOS7(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/centos-release", "/etc/redhat-release"}) {
 			return true, "CentOS Linux release 7.8.2003 (Core)\n"
 		} else {
@@ -434,7 +792,7 @@ func TestDiscoverCentOS7(t *testing.T) {
 
 func TestDiscoverCentOS8(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/centos-release", "/etc/redhat-release"}) {
 			return true, "CentOS Linux release 8.2.2004 (Core)\n"
 		} else {
@@ -467,6 +825,59 @@ func TestDiscoverCentOS8(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverChromeOS(t *testing.T) {
+	lsbProperties := map[string]string{
+		"CHROMEOS_AUSERVER":                 "https://tools.google.com/service/update2",
+		"CHROMEOS_BOARD_APPID":              "{01906EA2-3EB2-41F1-8F62-F0B7120EFD2E}",
+		"CHROMEOS_CANARY_APPID":             "{90F229CE-83E2-4FAF-8479-E368A34938B1}",
+		"CHROMEOS_RELEASE_APPID":            "{01906EA2-3EB2-41F1-8F62-F0B7120EFD2E}",
+		"CHROMEOS_RELEASE_BOARD":            "eve-signed-mp-v2keys",
+		"CHROMEOS_RELEASE_BRANCH_NUMBER":    "56",
+		"CHROMEOS_RELEASE_BUILDER_PATH":     "eve-release/R89-13729.56.0",
+		"CHROMEOS_RELEASE_BUILD_NUMBER":     "13729",
+		"CHROMEOS_RELEASE_BUILD_TYPE":       "Official Build",
+		"CHROMEOS_RELEASE_CHROME_MILESTONE": "89",
+		"CHROMEOS_RELEASE_DESCRIPTION":      "13729.56.0 (Official Build) stable-channel eve",
+		"CHROMEOS_RELEASE_KEYSET":           "mp-v2",
+		"CHROMEOS_RELEASE_NAME":             "Chrome OS",
+		"CHROMEOS_RELEASE_PATCH_NUMBER":     "0",
+		"CHROMEOS_RELEASE_TRACK":            "stable-channel",
+		"CHROMEOS_RELEASE_UNIBUILD":         "1",
+		"CHROMEOS_RELEASE_VERSION":          "13729.56.0",
+		"DEVICETYPE":                        "CHROMEBOOK",
+		"GOOGLE_RELEASE":                    "13729.56.0",
+	}
+	osReleaseProperties := map[string]string{
+		"BUILD_ID":        "13729.56.0",
+		"GOOGLE_CRASH_ID": "ChromeOS",
+		"HOME_URL":        "https://www.chromium.org/chromium-os",
+		"ID":              "chromeos",
+		"ID_LIKE":         "chromiumos",
+		"NAME":            "Chrome OS",
+		"VERSION":         "89",
+		"VERSION_ID":      "89",
+		"BUG_REPORT_URL":  "https://crbug.com/new",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "chromeos", "Chrome OS", "13729.56.0", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverChromeOSWithUbuntuLsbRelease(t *testing.T) {
+	lsbProperties := map[string]string{
+		"CHROMEOS_RELEASE_NAME":    "Chrome OS",
+		"CHROMEOS_RELEASE_VERSION": "14541.0.0",
+		"DISTRIB_ID":               "Ubuntu",
+		"DISTRIB_RELEASE":          "20.04",
+		"DISTRIB_CODENAME":         "focal",
+		"DISTRIB_DESCRIPTION":      "Ubuntu 20.04.4 LTS",
+	}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "chromeos", "Chrome OS", "14541.0.0", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverClearLinux(t *testing.T) {
 	lsbProperties := map[string]string{}
 	osReleaseProperties := map[string]string{
@@ -484,13 +895,93 @@ func TestDiscoverClearLinux(t *testing.T) {
 		"SUPPORT_URL":        "https://clearlinux.org",
 	}
 
-	distroIsDetectedBasedOnProperties(t, "clear-linux-os", "Clear Linux OS", "33910", lsbProperties,
+	distro := distroIsDetectedBasedOnProperties(t, "clear-linux-os", "Clear Linux OS", "33910", lsbProperties,
+		osReleaseProperties)
+	if distro.BuildID != "33910" {
+		t.Errorf("build id was not detected correctly. Expected (33910) was (%s).", distro.BuildID)
+	}
+}
+
+func TestDiscoverClearOS(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/clearos-release"}) {
+			return true, "ClearOS release 7.9.1 (Final)\n"
+		}
+		if reflect.DeepEqual(filePaths, []string{"/etc/centos-release", "/etc/redhat-release"}) {
+			return true, "CentOS Linux release 7.9.2009 (Core)\n"
+		}
+
+		return false, ""
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":                            "CentOS Linux",
+		"ID":                              "centos",
+		"ID_LIKE":                         "rhel fedora",
+		"VERSION_ID":                      "7",
+		"VERSION":                         "7 (Core)",
+		"PRETTY_NAME":                     "CentOS Linux 7 (Core)",
+		"CPE_NAME":                        "cpe:/o:centos:centos:7",
+		"HOME_URL":                        "https://www.centos.org/",
+		"BUG_REPORT_URL":                  "https://bugs.centos.org/",
+		"CENTOS_MANTISBT_PROJECT":         "CentOS-7",
+		"CENTOS_MANTISBT_PROJECT_VERSION": "7",
+		"REDHAT_SUPPORT_PRODUCT":          "centos",
+		"REDHAT_SUPPORT_PRODUCT_VERSION":  "7",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "clearos", "ClearOS", "7.9.1", lsbProperties,
+		osReleaseProperties)
+
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if !distro.IsRHELCompatible() {
+		t.Error("ClearOS was not RHEL compatible")
+	}
+	if !distro.UsesRPM() {
+		t.Error("ClearOS does not use RPM")
+	}
+}
+
+func TestDiscoverClonezilla(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/drbl/drbl.conf"}) {
+			return true, "# Setting for DRBL\n[general]\n"
+		} else if reflect.DeepEqual(filePaths, []string{"/etc/debian_version"}) {
+			return true, "12.2\n"
+		} else if reflect.DeepEqual(filePaths, []string{"/etc/issue"}) {
+			return true, "Debian GNU/Linux 12 \\n \\l\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"PRETTY_NAME":      "Debian GNU/Linux 12 (bookworm)",
+		"NAME":             "Debian GNU/Linux",
+		"VERSION_ID":       "12",
+		"VERSION":          "12 (bookworm)",
+		"VERSION_CODENAME": "bookworm",
+		"ID":               "debian",
+		"HOME_URL":         "https://www.debian.org/",
+		"SUPPORT_URL":      "https://www.debian.org/support",
+		"BUG_REPORT_URL":   "https://bugs.debian.org/",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "clonezilla", "Clonezilla Live", "unknown", lsbProperties,
 		osReleaseProperties)
 }
 
 func TestDiscoverCrux3(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/usr/bin/crux"}) {
 			return true, "#!/bin/sh\n\necho \"CRUX version 3.0\"\n\n# End of file\n"
 		} else {
@@ -509,7 +1000,7 @@ func TestDiscoverCrux3(t *testing.T) {
 
 func TestDiscoverDebian6(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		debianVersionPaths := []string{"/etc/debian_version"}
 		issuePaths := []string{"/etc/issue"}
 
@@ -534,7 +1025,7 @@ func TestDiscoverDebian6(t *testing.T) {
 
 func TestDiscoverDebian7(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		debianVersionPaths := []string{"/etc/debian_version"}
 		issuePaths := []string{"/etc/issue"}
 
@@ -568,7 +1059,7 @@ func TestDiscoverDebian7(t *testing.T) {
 
 func TestDiscoverDebian8(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		debianVersionPaths := []string{"/etc/debian_version"}
 		issuePaths := []string{"/etc/issue"}
 
@@ -601,7 +1092,7 @@ func TestDiscoverDebian8(t *testing.T) {
 
 func TestDiscoverDebian9(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		debianVersionPaths := []string{"/etc/debian_version"}
 		issuePaths := []string{"/etc/issue"}
 
@@ -635,7 +1126,7 @@ func TestDiscoverDebian9(t *testing.T) {
 
 func TestDiscoverDebian10(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		debianVersionPaths := []string{"/etc/debian_version"}
 		issuePaths := []string{"/etc/issue"}
 
@@ -667,10 +1158,99 @@ func TestDiscoverDebian10(t *testing.T) {
 		osReleaseProperties)
 }
 
-func TestDiscoverFedora20(t *testing.T) {
+func TestDiscoverDebian12CustomIssue(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
-		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release"}) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		debianVersionPaths := []string{"/etc/debian_version"}
+		issuePaths := []string{"/etc/issue"}
+
+		if reflect.DeepEqual(filePaths, debianVersionPaths) {
+			return true, "12.4\n"
+		} else if reflect.DeepEqual(filePaths, issuePaths) {
+			return true, "Authorized access only. All activity may be monitored and reported.\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"PRETTY_NAME":      "Debian GNU/Linux 12 (bookworm)",
+		"NAME":             "Debian GNU/Linux",
+		"VERSION_ID":       "12",
+		"VERSION":          "12 (bookworm)",
+		"VERSION_CODENAME": "bookworm",
+		"ID":               "debian",
+		"HOME_URL":         "https://www.debian.org/",
+		"SUPPORT_URL":      "https://www.debian.org/support",
+		"BUG_REPORT_URL":   "https://bugs.debian.org/",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "debian", "Debian GNU/Linux", "12.4", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverDebianTestingCodename(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/debian_version": "trixie\n",
+		"/etc/issue":          "Debian GNU/Linux trixie/sid \\n \\l\n",
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"PRETTY_NAME":      "Debian GNU/Linux trixie/sid",
+		"NAME":             "Debian GNU/Linux",
+		"VERSION_CODENAME": "trixie",
+		"ID":               "debian",
+		"HOME_URL":         "https://www.debian.org/",
+	}
+
+	distro := distroIsDetectedBasedOnProperties(t, "debian", "Debian GNU/Linux", "trixie", lsbProperties,
+		osReleaseProperties)
+	if distro.NumericVersion != "13" {
+		t.Errorf("numeric version was not detected correctly. Expected (13) was (%s).", distro.NumericVersion)
+	}
+}
+
+func TestDiscoverDebianCustomIssueWithoutOSRelease(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/debian_version"}) {
+			return true, "12.4\n"
+		} else if reflect.DeepEqual(filePaths, []string{"/etc/issue"}) {
+			return true, "Welcome to a Debian derivative\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+
+	matched, _ := isDebian(NewDetector(), map[string]string{}, map[string]string{})
+	if matched {
+		t.Error("a non-Debian issue file without an os-release file should not be detected as Debian")
+	}
+}
+
+func TestDiscoverDebianFromCodename(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"PRETTY_NAME":      "Debian GNU/Linux",
+		"NAME":             "Debian GNU/Linux",
+		"VERSION_CODENAME": "bookworm",
+		"ID":               "debian",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "debian", "Debian GNU/Linux", "12", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverFedora20(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release"}) {
 			return true, "Fedora release 20 (Heisenbug)\n"
 		} else {
 			return false, ""
@@ -700,9 +1280,89 @@ func TestDiscoverFedora20(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverFedoraSilverblueImage(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":           "Fedora Linux",
+		"VERSION":        "39.20240301.0 (Silverblue)",
+		"ID":             "fedora",
+		"VERSION_ID":     "39",
+		"PLATFORM_ID":    "platform:f39",
+		"PRETTY_NAME":    "Bluefin (Version: 39.20240301.0)",
+		"VARIANT":        "Silverblue",
+		"VARIANT_ID":     "silverblue",
+		"IMAGE_ID":       "bluefin",
+		"IMAGE_VERSION":  "39.20240301.0",
+		"OSTREE_VERSION": "39.20240301.0",
+	}
+
+	distro := distroIsDetectedBasedOnProperties(t, "fedora", "Fedora", "39", lsbProperties,
+		osReleaseProperties)
+	if distro.ImageID != "bluefin" {
+		t.Errorf("image id was not detected correctly. Expected (bluefin) was (%s).", distro.ImageID)
+	}
+	if distro.ImageVersion != "39.20240301.0" {
+		t.Errorf("image version was not detected correctly. Expected (39.20240301.0) was (%s).",
+			distro.ImageVersion)
+	}
+
+	delete(osReleaseProperties, "IMAGE_ID")
+	delete(osReleaseProperties, "IMAGE_VERSION")
+	distro = NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if distro.ImageID != "" || distro.ImageVersion != "" {
+		t.Errorf("image was detected without IMAGE_ID or IMAGE_VERSION: %s %s", distro.ImageID,
+			distro.ImageVersion)
+	}
+}
+
+func TestDiscoverFedoraWithoutOsReleaseId(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":       "Fedora",
+		"VERSION":    "17 (Beefy Miracle)",
+		"VERSION_ID": "17",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "fedora", "Fedora", "17", lsbProperties,
+		osReleaseProperties)
+
+	if _, ok := osReleaseProperties["ID"]; ok {
+		t.Error("synthesized ID was added to the os-release properties")
+	}
+}
+
+func TestDiscoverFreespire(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/debian_version"}) {
+			return true, "buster/sid\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":           "Freespire",
+		"VERSION":        "6.0",
+		"ID":             "freespire",
+		"ID_LIKE":        "debian",
+		"PRETTY_NAME":    "Freespire 6.0",
+		"VERSION_ID":     "6.0",
+		"HOME_URL":       "https://www.freespire.net/",
+		"SUPPORT_URL":    "https://www.freespire.net/",
+		"BUG_REPORT_URL": "https://www.freespire.net/",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "freespire", "Freespire", "6.0", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverGentoo1(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/gentoo-release"}) {
 			return true, "Gentoo Base System version 1.6.14\n"
 		} else {
@@ -729,7 +1389,7 @@ func TestDiscoverGentoo1(t *testing.T) {
 
 func TestDiscoverGentoo2(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/gentoo-release"}) {
 			return true, "Gentoo Base System release 2.6\n"
 		} else {
@@ -754,6 +1414,25 @@ func TestDiscoverGentoo2(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverHyperbola(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":           "Hyperbola GNU/Linux-libre",
+		"PRETTY_NAME":    "Hyperbola GNU/Linux-libre v0.4",
+		"ID":             "hyperbola",
+		"ID_LIKE":        "arch",
+		"VERSION":        "v0.4",
+		"VERSION_ID":     "0.4",
+		"ANSI_COLOR":     "0;35",
+		"HOME_URL":       "https://www.hyperbola.info/",
+		"SUPPORT_URL":    "https://wiki.hyperbola.info/",
+		"BUG_REPORT_URL": "https://issues.hyperbola.info/",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "hyperbola", "Hyperbola GNU/Linux-libre", "0.4", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverKali(t *testing.T) {
 	lsbProperties := map[string]string{}
 	osReleaseProperties := map[string]string{
@@ -776,7 +1455,7 @@ func TestDiscoverKali(t *testing.T) {
 
 func TestDiscoverRHEL6(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release", "/etc/redhat-version"}) {
 			return true, "Red Hat Enterprise Linux Server release 6.5 (Santiago)\n"
 		} else {
@@ -796,7 +1475,7 @@ func TestDiscoverRHEL6(t *testing.T) {
 
 func TestDiscoverRHEL7(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release", "/etc/redhat-version"}) {
 			return true, "Red Hat Enterprise Linux Server release 7.6 (Maipo)\n"
 		} else {
@@ -826,8 +1505,150 @@ func TestDiscoverRHEL7(t *testing.T) {
 		"VARIANT_ID":                      "server",
 	}
 
-	distroIsDetectedBasedOnProperties(t, "rhel", "Red Hat Enterprise Linux", "7.6", lsbProperties,
+	distro := distroIsDetectedBasedOnProperties(t, "rhel", "Red Hat Enterprise Linux Server", "7.6", lsbProperties,
+		osReleaseProperties)
+	if distro.Edition != "server" {
+		t.Errorf("Linux distro edition was not detected correctly. Expected (server) was (%s).", distro.Edition)
+	}
+}
+
+func TestDiscoverRHEL7Workstation(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release", "/etc/redhat-version"}) {
+			return true, "Red Hat Enterprise Linux Workstation release 7.9 (Maipo)\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":                   "Red Hat Enterprise Linux Workstation",
+		"VERSION":                "7.9 (Maipo)",
+		"ID":                     "rhel",
+		"ID_LIKE":                "fedora",
+		"VARIANT":                "Workstation",
+		"VARIANT_ID":             "workstation",
+		"VERSION_ID":             "7.9",
+		"PRETTY_NAME":            "Red Hat Enterprise Linux Workstation 7.9 (Maipo)",
+		"CPE_NAME":               "cpe:/o:redhat:enterprise_linux:7.9:GA:workstation",
+		"REDHAT_SUPPORT_PRODUCT": "Red Hat Enterprise Linux 7",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "rhel", "Red Hat Enterprise Linux Workstation", "7.9", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverRHEL9(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":                           "Red Hat Enterprise Linux",
+		"VERSION":                        "9.2 (Plow)",
+		"ID":                             "rhel",
+		"ID_LIKE":                        "fedora",
+		"VERSION_ID":                     "9.2",
+		"PLATFORM_ID":                    "platform:el9",
+		"PRETTY_NAME":                    "Red Hat Enterprise Linux 9.2 (Plow)",
+		"ANSI_COLOR":                     "0;31",
+		"LOGO":                           "fedora-logo-icon",
+		"CPE_NAME":                       "cpe:/o:redhat:enterprise_linux:9::baseos",
+		"HOME_URL":                       "https://www.redhat.com/",
+		"BUG_REPORT_URL":                 "https://bugzilla.redhat.com/",
+		"REDHAT_SUPPORT_PRODUCT":         "Red Hat Enterprise Linux",
+		"REDHAT_SUPPORT_PRODUCT_VERSION": "9.2",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "rhel", "Red Hat Enterprise Linux", "9.2", lsbProperties,
+		osReleaseProperties)
+
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if distro.PlatformID() != "platform:el9" {
+		t.Errorf("platform id was not detected correctly. Expected (%s) was (%s).", "platform:el9",
+			distro.PlatformID())
+	}
+}
+
+func TestDiscoverRHEL9AmbiguousReleaseFile(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release", "/etc/redhat-version"}) {
+			return true, "Red Hat Enterprise Linux release (Plow)\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":        "Red Hat Enterprise Linux",
+		"PLATFORM_ID": "platform:el9",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "rhel", "Red Hat Enterprise Linux", "9", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverRHELUBI9Micro(t *testing.T) {
+	// UBI micro container images strip /etc/redhat-release, so only os-release is available
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":                            "Red Hat Enterprise Linux",
+		"VERSION":                         "9.3 (Plow)",
+		"ID":                              "rhel",
+		"ID_LIKE":                         "fedora",
+		"VERSION_ID":                      "9.3",
+		"PLATFORM_ID":                     "platform:el9",
+		"PRETTY_NAME":                     "Red Hat Enterprise Linux 9.3 (Plow)",
+		"ANSI_COLOR":                      "0;31",
+		"LOGO":                            "fedora-logo-icon",
+		"CPE_NAME":                        "cpe:/o:redhat:enterprise_linux:9::baseos",
+		"HOME_URL":                        "https://www.redhat.com/",
+		"DOCUMENTATION_URL":               "https://access.redhat.com/documentation/en-us/red_hat_enterprise_linux/9",
+		"BUG_REPORT_URL":                  "https://bugzilla.redhat.com/",
+		"REDHAT_BUGZILLA_PRODUCT":         "Red Hat Enterprise Linux 9",
+		"REDHAT_BUGZILLA_PRODUCT_VERSION": "9.3",
+		"REDHAT_SUPPORT_PRODUCT":          "Red Hat Enterprise Linux",
+		"REDHAT_SUPPORT_PRODUCT_VERSION":  "9.3",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "rhel", "Red Hat Enterprise Linux", "9.3", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverLibertyLinux(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":                           "SUSE Liberty Linux",
+		"VERSION":                        "8.9 (Ootpa)",
+		"ID":                             "rhel",
+		"ID_LIKE":                        "fedora",
+		"VERSION_ID":                     "8.9",
+		"PLATFORM_ID":                    "platform:el8",
+		"PRETTY_NAME":                    "SUSE Liberty Linux 8.9 (Ootpa)",
+		"ANSI_COLOR":                     "0;31",
+		"CPE_NAME":                       "cpe:/o:suse:liberty:8::baseos",
+		"HOME_URL":                       "https://www.suse.com/products/suse-liberty-linux/",
+		"SUPPORT_URL":                    "https://www.suse.com/support/",
+		"BUG_REPORT_URL":                 "https://bugzilla.suse.com/",
+		"REDHAT_SUPPORT_PRODUCT":         "SUSE Liberty Linux",
+		"REDHAT_SUPPORT_PRODUCT_VERSION": "8.9",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "liberty", "SUSE Liberty Linux", "8.9", lsbProperties,
 		osReleaseProperties)
+
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if !distro.IsRHELCompatible() {
+		t.Error("SUSE Liberty Linux should be RHEL compatible")
+	}
+	if !distro.UsesRPM() {
+		t.Error("SUSE Liberty Linux should use RPM")
+	}
 }
 
 func TestDiscoverMageia(t *testing.T) {
@@ -853,6 +1674,82 @@ func TestDiscoverMageia(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverMageia8(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":               "Mageia",
+		"VERSION":            "8",
+		"ID":                 "mageia",
+		"VERSION_ID":         "8",
+		"ID_LIKE":            "mandriva fedora",
+		"PRETTY_NAME":        "Mageia 8",
+		"ANSI_COLOR":         "1;36",
+		"HOME_URL":           "http://www.mageia.org/",
+		"SUPPORT_URL":        "http://www.mageia.org/support/",
+		"BUG_REPORT_URL":     "https://bugs.mageia.org/",
+		"PRIVACY_POLICY_URL": "https://wiki.mageia.org/en/Privacy_policy",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "mageia", "Mageia", "8", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverMageiaLsbOnly(t *testing.T) {
+	lsbProperties := map[string]string{
+		"DISTRIB_ID":          "Mageia",
+		"DISTRIB_RELEASE":     "2",
+		"DISTRIB_CODENAME":    "turtle",
+		"DISTRIB_DESCRIPTION": "Mageia 2",
+	}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "mageia", "Mageia", "2", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverMandrake(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/mandriva-release", "/etc/mandrake-release"}) {
+			return true, "Mandrake Linux release 10.0 (Official) for i586\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "mandriva", "Mandriva Linux", "10.0", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverMandriva(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/mandriva-release", "/etc/mandrake-release"}) {
+			return true, "Mandriva Linux release 2011.0 (turtle) for x86_64\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "mandriva", "Mandriva Linux", "2011.0", lsbProperties,
+		osReleaseProperties)
+
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if !distro.UsesRPM() {
+		t.Error("Mandriva Linux should use RPM")
+	}
+}
+
 func TestDiscoverMint(t *testing.T) {
 	lsbProperties := map[string]string{
 		"DISTRIB_ID":          "LinuxMint",
@@ -879,9 +1776,26 @@ func TestDiscoverMint(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverMintWithoutLsbRelease(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":             "Linux Mint",
+		"VERSION":          "21.3 (Virginia)",
+		"ID":               "linuxmint",
+		"ID_LIKE":          "ubuntu debian",
+		"PRETTY_NAME":      "Linux Mint 21.3",
+		"VERSION_ID":       "21.3",
+		"VERSION_CODENAME": "virginia",
+		"UBUNTU_CODENAME":  "jammy",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "linuxmint", "Linux Mint", "21.3", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverMXLinuxOld(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		debianVersionPaths := []string{"/etc/debian_version"}
 		issuePaths := []string{"/etc/issue"}
 		mxVersionPaths := []string{"/etc/mx-version"}
@@ -910,7 +1824,7 @@ func TestDiscoverMXLinuxOld(t *testing.T) {
 
 func TestDiscoverMXLinux(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		debianVersionPaths := []string{"/etc/debian_version"}
 		issuePaths := []string{"/etc/issue"}
 		mxVersionPaths := []string{"/etc/mx-version"}
@@ -953,53 +1867,114 @@ func TestDiscoverMXLinux(t *testing.T) {
 		osReleaseProperties)
 }
 
-func TestDiscoverNix(t *testing.T) {
+func TestDiscoverMXLinuxWithoutLsbRelease(t *testing.T) {
 	lsbProperties := map[string]string{}
 	osReleaseProperties := map[string]string{
-		"DOCUMENTATION_URL": "https://nixos.org/learn.html",
-		"NAME":              "NixOS",
-		"VERSION":           "20.09.1889.58f9c4c7d3a (Nightingale)",
-		"PRETTY_NAME":       "NixOS 20.09 (Nightingale)",
-		"LOGO":              "nix-snowflake",
-		"HOME_URL":          "https://nixos.org/",
-		"SUPPORT_URL":       "https://nixos.org/community.html",
-		"BUG_REPORT_URL":    "https://github.com/NixOS/nixpkgs/issues",
-		"ID":                "nixos",
-		"VERSION_CODENAME":  "nightingale",
-		"VERSION_ID":        "20.09.1889.58f9c4c7d3a",
+		"PRETTY_NAME":      "MX Linux 23 (libretto)",
+		"NAME":             "MX Linux",
+		"VERSION_ID":       "23",
+		"VERSION":          "23 (libretto)",
+		"VERSION_CODENAME": "bookworm",
+		"ID":               "mx",
+		"ID_LIKE":          "debian",
+		"HOME_URL":         "https://mxlinux.org/",
 	}
 
-	distroIsDetectedBasedOnProperties(t, "nixos", "NixOS", "20.09.1889.58f9c4c7d3a", lsbProperties,
+	distroIsDetectedBasedOnProperties(t, "mx", "MX Linux", "23", lsbProperties,
 		osReleaseProperties)
 }
 
-func TestDiscoverNovellOES(t *testing.T) {
+func TestDiscoverNethServer(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
-		if reflect.DeepEqual(filePaths, []string{"/etc/novell-release"}) {
-			return true, "Novell Open Enterprise Server 2.0.1 (i586)\nVERSION = 2.0.1\nPATCHLEVEL = 1\nBUILD\n"
-		} else {
-			return false, ""
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/nethserver-release"}) {
+			return true, "NethServer release 7.9.2009 (final)\n"
+		}
+		if reflect.DeepEqual(filePaths, []string{"/etc/centos-release", "/etc/redhat-release"}) {
+			return true, "CentOS Linux release 7.9.2009 (Core)\n"
 		}
+
+		return false, ""
 	}
 	t.Cleanup(func() {
 		readFileFunc = originalReadFileFunc
 	})
 	lsbProperties := map[string]string{}
-	osReleaseProperties := map[string]string{}
-
-	distroIsDetectedBasedOnProperties(t, "oes", "Novell Open Enterprise Server", "2.0.1", lsbProperties,
-		osReleaseProperties)
-}
-
-// TestOpenSuSEOld tests versions of Open SuSE that don't have a /etc/os-release file.
-func TestDiscoverOpenSuSEOld(t *testing.T) {
-	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
-		if reflect.DeepEqual(filePaths, []string{"/etc/SuSE-release"}) {
-			return true, "openSUSE 42.1 (x86_64)\nVERSION = 42.1\nCODENAME = Malachite\n# /etc/SuSE-release is deprecated and will be removed in the future, use /etc/os-release instead\n"
-		} else {
-			return false, ""
+	osReleaseProperties := map[string]string{
+		"NAME":                            "CentOS Linux",
+		"ID":                              "centos",
+		"ID_LIKE":                         "rhel fedora",
+		"VERSION_ID":                      "7",
+		"VERSION":                         "7 (Core)",
+		"PRETTY_NAME":                     "CentOS Linux 7 (Core)",
+		"CPE_NAME":                        "cpe:/o:centos:centos:7",
+		"HOME_URL":                        "https://www.centos.org/",
+		"BUG_REPORT_URL":                  "https://bugs.centos.org/",
+		"CENTOS_MANTISBT_PROJECT":         "CentOS-7",
+		"CENTOS_MANTISBT_PROJECT_VERSION": "7",
+		"REDHAT_SUPPORT_PRODUCT":          "centos",
+		"REDHAT_SUPPORT_PRODUCT_VERSION":  "7",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "nethserver", "NethServer", "7.9.2009", lsbProperties,
+		osReleaseProperties)
+
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if !distro.IsRHELCompatible() {
+		t.Error("NethServer was not RHEL compatible")
+	}
+	if !distro.UsesRPM() {
+		t.Error("NethServer does not use RPM")
+	}
+}
+
+func TestDiscoverNix(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"DOCUMENTATION_URL": "https://nixos.org/learn.html",
+		"NAME":              "NixOS",
+		"VERSION":           "20.09.1889.58f9c4c7d3a (Nightingale)",
+		"PRETTY_NAME":       "NixOS 20.09 (Nightingale)",
+		"LOGO":              "nix-snowflake",
+		"HOME_URL":          "https://nixos.org/",
+		"SUPPORT_URL":       "https://nixos.org/community.html",
+		"BUG_REPORT_URL":    "https://github.com/NixOS/nixpkgs/issues",
+		"ID":                "nixos",
+		"VERSION_CODENAME":  "nightingale",
+		"VERSION_ID":        "20.09.1889.58f9c4c7d3a",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "nixos", "NixOS", "20.09.1889.58f9c4c7d3a", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverNovellOES(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/novell-release"}) {
+			return true, "Novell Open Enterprise Server 2.0.1 (i586)\nVERSION = 2.0.1\nPATCHLEVEL = 1\nBUILD\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "oes", "Novell Open Enterprise Server", "2.0.1", lsbProperties,
+		osReleaseProperties)
+}
+
+// TestOpenSuSEOld tests versions of Open SuSE that don't have a /etc/os-release file.
+func TestDiscoverOpenSuSEOld(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/SuSE-release"}) {
+			return true, "openSUSE 42.1 (x86_64)\nVERSION = 42.1\nCODENAME = Malachite\n# /etc/SuSE-release is deprecated and will be removed in the future, use /etc/os-release instead\n"
+		} else {
+			return false, ""
 		}
 	}
 	t.Cleanup(func() {
@@ -1014,7 +1989,7 @@ func TestDiscoverOpenSuSEOld(t *testing.T) {
 
 func TestDiscoverOpenSuSE42(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/SuSE-release"}) {
 			return true, "openSUSE 42.1 (x86_64)\nVERSION = 42.1\nCODENAME = Malachite\n# /etc/SuSE-release is deprecated and will be removed in the future, use /etc/os-release instead\n"
 		} else {
@@ -1042,9 +2017,51 @@ func TestDiscoverOpenSuSE42(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverOpenSuSEWithoutVersionId(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":        "openSUSE Leap",
+		"VERSION":     "15.5",
+		"ID":          "opensuse",
+		"ID_LIKE":     "suse opensuse",
+		"PRETTY_NAME": "openSUSE Leap 15.5",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "opensuse", "openSUSE", "15.5", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverOpenSuSELeapWithoutVersionId(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":        "openSUSE Leap",
+		"VERSION":     "15.5",
+		"ID":          "opensuse-leap",
+		"ID_LIKE":     "suse opensuse",
+		"PRETTY_NAME": "openSUSE Leap 15.5",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "opensuse-leap", "openSUSE", "15.5", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverOpenSuSETumbleweedWithoutVersionId(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":        "openSUSE Tumbleweed",
+		"VERSION":     "20240101",
+		"ID":          "opensuse-tumbleweed",
+		"ID_LIKE":     "opensuse suse",
+		"PRETTY_NAME": "openSUSE Tumbleweed",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "opensuse-tumbleweed", "openSUSE", "20240101", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverOracleLinux6(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release"}) {
 			// Yes - of course, Oracle Linux impersonates Red Hat if you try to read /etc/redhat-release
 			return true, "Red Hat Enterprise Linux Server release 6.10 (Santiago)\n"
@@ -1082,7 +2099,7 @@ func TestDiscoverOracleLinux6(t *testing.T) {
 
 func TestDiscoverOracleLinux7(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release"}) {
 			// Yes - of course, Oracle Linux impersonates Red Hat if you try to read /etc/redhat-release
 			return true, "Red Hat Enterprise Linux Server release 7.9 (Maipo)\n"
@@ -1123,7 +2140,7 @@ func TestDiscoverOracleLinux7(t *testing.T) {
 
 func TestDiscoverOracleLinux8(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release"}) {
 			// Yes - of course, Oracle Linux impersonates Red Hat if you try to read /etc/redhat-release
 			return true, "Oracle Linux Server release 7.9\n"
@@ -1162,6 +2179,54 @@ func TestDiscoverOracleLinux8(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverOracleLinuxWithoutVersionId(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":                           "Oracle Linux Server",
+		"ID":                             "ol",
+		"ID_LIKE":                        "fedora",
+		"VERSION_ID":                     "",
+		"PLATFORM_ID":                    "platform:el8",
+		"ORACLE_SUPPORT_PRODUCT":         "Oracle Linux",
+		"ORACLE_SUPPORT_PRODUCT_VERSION": "8.7",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "ol", "Oracle Linux", "8.7", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverOracleLinux5WithEnterpriseEnterpriseLsbRelease(t *testing.T) {
+	lsbProperties := map[string]string{
+		"LSB_VERSION":         "core-4.0-amd64:core-4.0-noarch",
+		"DISTRIB_ID":          "EnterpriseEnterpriseServer",
+		"DISTRIB_DESCRIPTION": "Enterprise Linux Enterprise Linux Server release 5.8 (Carthage)",
+		"DISTRIB_RELEASE":     "5.8",
+		"DISTRIB_CODENAME":    "Carthage",
+	}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "ol", "Oracle Linux", "5.8", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverParabola(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":           "Parabola GNU/Linux-libre",
+		"PRETTY_NAME":    "Parabola GNU/Linux-libre",
+		"ID":             "parabola",
+		"ID_LIKE":        "arch",
+		"ANSI_COLOR":     "1;35",
+		"HOME_URL":       "https://www.parabola.nu/",
+		"SUPPORT_URL":    "https://wiki.parabola.nu/",
+		"BUG_REPORT_URL": "https://labs.parabola.nu/",
+		"LOGO":           "parabola",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "parabola", "Parabola GNU/Linux-libre", "rolling", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverPhoton(t *testing.T) {
 	lsbProperties := map[string]string{
 		"DISTRIB_RELEASE":     "1.0",
@@ -1184,6 +2249,67 @@ func TestDiscoverPhoton(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverPhoton4(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/photon-release"}) {
+			return true, "VMware Photon OS 4.0\nPHOTON_BUILD_NUMBER=1526e30ba0\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "photon", "VMware Photon", "4.0", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverPhotonWithoutVersion(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/photon-release": "VMware Photon OS\nPHOTON_BUILD_NUMBER=1526e30ba0\n",
+	})
+
+	detected, distro := isPhoton(NewDetector(), ReleaseDetails{}, ReleaseDetails{})
+	if !detected {
+		t.Fatal("VMware Photon was not detected")
+	}
+	if distro.Version != "" {
+		t.Errorf("Linux distro version was not detected correctly. Expected () was (%s).", distro.Version)
+	}
+}
+
+func TestDiscoverPhotonWithBuildSuffix(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/photon-release": "VMware Photon OS 4.0 GA\nPHOTON_BUILD_NUMBER=1526e30ba0\n",
+	})
+
+	_, distro := isPhoton(NewDetector(), ReleaseDetails{}, ReleaseDetails{})
+	if distro.Version != "4.0" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (4.0) was (%s).", distro.Version)
+	}
+}
+
+func TestDiscoverPhoton5(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":           "VMware Photon OS",
+		"VERSION":        "5.0",
+		"ID":             "photon",
+		"VERSION_ID":     "5.0",
+		"PRETTY_NAME":    "VMware Photon OS/Linux",
+		"ANSI_COLOR":     "1;34",
+		"HOME_URL":       "https://vmware.github.io/photon/",
+		"BUG_REPORT_URL": "https://github.com/vmware/photon/issues",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "photon", "VMware Photon", "5.0", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverPuppy(t *testing.T) {
 	lsbProperties := map[string]string{
 		"DISTRIB_DESCRIPTION": "FossaPup64 9.0",
@@ -1208,6 +2334,73 @@ func TestDiscoverPuppy(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverPuppyWithoutLsbRelease(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":        "Puppy",
+		"VERSION":     "9.5",
+		"ID":          "puppy_fossapup64",
+		"VERSION_ID":  "9.5",
+		"PRETTY_NAME": "fossapup64 9.5",
+		"HOME_URL":    "http://puppylinux.com/",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "puppy", "Puppy Linux", "9.5", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverRaspberryPiOS(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"PRETTY_NAME":      "Raspbian GNU/Linux 10 (buster)",
+		"NAME":             "Raspbian GNU/Linux",
+		"VERSION_ID":       "10",
+		"VERSION":          "10 (buster)",
+		"VERSION_CODENAME": "buster",
+		"ID":               "raspbian",
+		"ID_LIKE":          "debian",
+		"HOME_URL":         "http://www.raspbian.org/",
+		"SUPPORT_URL":      "http://www.raspbian.org/RaspbianForums",
+		"BUG_REPORT_URL":   "http://www.raspbian.org/RaspbianBugs",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "raspbian", "Raspberry Pi OS", "10", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverRaspberryPiOSFromSourcesList(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/apt/sources.list"}) {
+			return true, "deb http://raspbian.raspberrypi.org/raspbian/ bookworm main contrib non-free rpi\n"
+		} else if reflect.DeepEqual(filePaths, []string{"/etc/debian_version"}) {
+			return true, "12.4\n"
+		} else if reflect.DeepEqual(filePaths, []string{"/etc/issue"}) {
+			return true, "Debian GNU/Linux 12 \\n \\l\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"PRETTY_NAME":      "Debian GNU/Linux 12 (bookworm)",
+		"NAME":             "Debian GNU/Linux",
+		"VERSION_ID":       "12",
+		"VERSION":          "12 (bookworm)",
+		"VERSION_CODENAME": "bookworm",
+		"ID":               "debian",
+		"HOME_URL":         "https://www.debian.org/",
+		"SUPPORT_URL":      "https://www.debian.org/support",
+		"BUG_REPORT_URL":   "https://bugs.debian.org/",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "raspbian", "Raspberry Pi OS", "12", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverRancherOS(t *testing.T) {
 	lsbProperties := map[string]string{
 		"DISTRIB_ID":          "RancherOS",
@@ -1229,9 +2422,39 @@ func TestDiscoverRancherOS(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverSalix(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/salix-version"}) {
+			return true, "Salix 15.0\n"
+		} else if reflect.DeepEqual(filePaths, []string{"/etc/slackware-version"}) {
+			return true, "Slackware 15.0\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":        "Slackware",
+		"VERSION":     "15.0",
+		"ID":          "slackware",
+		"VERSION_ID":  "15.0",
+		"PRETTY_NAME": "Slackware 15.0 x86_64",
+		"ANSI_COLOR":  "0;34",
+		"CPE_NAME":    "cpe:/o:slackware:slackware_linux:15.0",
+		"HOME_URL":    "http://slackware.com/",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "salix", "Salix OS", "15.0", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverScientificLinux6(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/sl-release", "/etc/redhat-release"}) {
 			return true, "Scientific Linux release 6.10 (Carbon)\n"
 		} else {
@@ -1251,7 +2474,7 @@ func TestDiscoverScientificLinux6(t *testing.T) {
 
 func TestDiscoverScientificLinux7(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/sl-release", "/etc/redhat-release"}) {
 			return true, "Scientific Linux release 7.9 (Nitrogen)\n"
 		} else {
@@ -1283,9 +2506,22 @@ func TestDiscoverScientificLinux7(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverSerpentOS(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":        "Serpent OS",
+		"ID":          "serpent",
+		"PRETTY_NAME": "Serpent OS",
+		"HOME_URL":    "https://serpentos.com",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "serpent", "Serpent OS", "rolling", lsbProperties,
+		osReleaseProperties)
+}
+
 func TestDiscoverSLESOld(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/SuSE-release", "/etc/sles-release"}) {
 			return true, "SUSE Linux Enterprise Server 12 (x86_64)\nVERSION = 12\nPATCHLEVEL = 1\n"
 		} else {
@@ -1304,7 +2540,7 @@ func TestDiscoverSLESOld(t *testing.T) {
 
 func TestDiscoverSLES12(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/SuSE-release"}) {
 			return true, "SUSE Linux Enterprise Server 12 (x86_64)\nVERSION = 12\nPATCHLEVEL = 1\n"
 		} else {
@@ -1331,7 +2567,7 @@ func TestDiscoverSLES12(t *testing.T) {
 
 func TestDiscoverSlackwareOld(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/slackware-version"}) {
 			return true, "Slackware 14.1"
 		} else {
@@ -1350,7 +2586,7 @@ func TestDiscoverSlackwareOld(t *testing.T) {
 
 func TestDiscoverSlackware14(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/slackware-version"}) {
 			return true, "Slackware 14.1"
 		} else {
@@ -1378,9 +2614,31 @@ func TestDiscoverSlackware14(t *testing.T) {
 		osReleaseProperties)
 }
 
+func TestDiscoverSlackwareCurrent(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":             "Slackware",
+		"VERSION":          "15.0",
+		"ID":               "slackware",
+		"VERSION_ID":       "15.0",
+		"PRETTY_NAME":      "Slackware 15.0 x86_64 (post 15.0 -current)",
+		"ANSI_COLOR":       "0;34",
+		"CPE_NAME":         "cpe:/o:slackware:slackware_linux:15.0",
+		"HOME_URL":         "http://slackware.com/",
+		"VERSION_CODENAME": "current",
+		"BUILD_ID":         "20240301",
+	}
+
+	distro := distroIsDetectedBasedOnProperties(t, "slackware", "Slackware", "current", lsbProperties,
+		osReleaseProperties)
+	if distro.BuildID != "20240301" {
+		t.Errorf("build id was not detected correctly. Expected (20240301) was (%s).", distro.BuildID)
+	}
+}
+
 func TestDiscoverSourceMage(t *testing.T) {
 	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
 		if reflect.DeepEqual(filePaths, []string{"/etc/sourcemage-release"}) {
 			return true, "Source Mage GNU/Linux x86_64-pc-linux-gnu\nInstalled from tarball using chroot image (Grimoire 0.62-stable) generated on Thu Dec  1 01:34:47 UTC 2016\n"
 		} else {
@@ -1393,7 +2651,59 @@ func TestDiscoverSourceMage(t *testing.T) {
 	lsbProperties := map[string]string{}
 	osReleaseProperties := map[string]string{}
 
-	distroIsDetectedBasedOnProperties(t, "sourcemage", "Source Mage GNU/Linux", "Grimoire 0.62-stable", lsbProperties,
+	distro := distroIsDetectedBasedOnProperties(t, "sourcemage", "Source Mage GNU/Linux", "0.62-stable",
+		lsbProperties, osReleaseProperties)
+	if distro.ReportedVersion != "Grimoire 0.62-stable" {
+		t.Errorf("reported version was not detected correctly. Expected (Grimoire 0.62-stable) was (%s).",
+			distro.ReportedVersion)
+	}
+}
+
+func TestDiscoverSystemRescue(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":              "SystemRescue",
+		"PRETTY_NAME":       "SystemRescue",
+		"ID":                "systemrescue",
+		"ID_LIKE":           "arch",
+		"VERSION_ID":        "10.02",
+		"BUILD_ID":          "rolling",
+		"ANSI_COLOR":        "38;2;23;147;209",
+		"HOME_URL":          "https://www.system-rescue.org/",
+		"DOCUMENTATION_URL": "https://www.system-rescue.org/manual/",
+		"SUPPORT_URL":       "https://www.system-rescue.org/forums/",
+		"BUG_REPORT_URL":    "https://gitlab.com/systemrescue/systemrescue-sources/-/issues",
+		"LOGO":              "archlinux-logo",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "systemrescue", "SystemRescue", "10.02", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverTuxedoOS(t *testing.T) {
+	lsbProperties := map[string]string{
+		"DISTRIB_ID":          "Ubuntu",
+		"DISTRIB_RELEASE":     "22.04",
+		"DISTRIB_CODENAME":    "jammy",
+		"DISTRIB_DESCRIPTION": "TUXEDO OS 2",
+	}
+	osReleaseProperties := map[string]string{
+		"PRETTY_NAME":        "TUXEDO OS 2",
+		"NAME":               "TUXEDO OS",
+		"VERSION_ID":         "22.04",
+		"VERSION":            "22.04.3 LTS (Jammy Jellyfish)",
+		"VERSION_CODENAME":   "jammy",
+		"ID":                 "tuxedo",
+		"ID_LIKE":            "ubuntu debian",
+		"HOME_URL":           "https://tuxedocomputers.com/",
+		"SUPPORT_URL":        "https://www.tuxedocomputers.com/en/TUXEDO-OS-Support",
+		"BUG_REPORT_URL":     "https://gitlab.com/tuxedocomputers/development/tuxedo-os/-/issues",
+		"PRIVACY_POLICY_URL": "https://www.tuxedocomputers.com/en/Privacy-policy.tuxedo",
+		"UBUNTU_CODENAME":    "jammy",
+		"LOGO":               "tuxedo-os",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "tuxedo", "TUXEDO OS", "22.04", lsbProperties,
 		osReleaseProperties)
 }
 
@@ -1494,33 +2804,2152 @@ func TestDiscoverUbuntu2004(t *testing.T) {
 		"UBUNTU_CODENAME":    "focal",
 	}
 
-	distroIsDetectedBasedOnProperties(t, "ubuntu", "Ubuntu", "20.04", lsbProperties,
+	distro := distroIsDetectedBasedOnProperties(t, "ubuntu", "Ubuntu", "20.04", lsbProperties,
 		osReleaseProperties)
+	if !distro.IsLTS {
+		t.Error("Ubuntu 20.04 was not detected as an LTS release")
+	}
 }
 
-func TestDiscoverYellowDog(t *testing.T) {
-	originalReadFileFunc := readFileFunc
-	readFileFunc = func(filePaths ...string) (bool, string) {
-		if reflect.DeepEqual(filePaths, []string{"/etc/yellowdog-release"}) {
-			return true, "Yellow Dog Linux release 4.0 (Orion)\n"
-		} else {
-			return false, ""
-		}
+func TestDiscoverUbuntu2110(t *testing.T) {
+	lsbProperties := map[string]string{
+		"DISTRIB_ID":          "Ubuntu",
+		"DISTRIB_RELEASE":     "21.10",
+		"DISTRIB_CODENAME":    "impish",
+		"DISTRIB_DESCRIPTION": "Ubuntu 21.10",
+	}
+	osReleaseProperties := map[string]string{
+		"PRETTY_NAME":        "Ubuntu 21.10",
+		"NAME":               "Ubuntu",
+		"VERSION_ID":         "21.10",
+		"VERSION":            "21.10 (Impish Indri)",
+		"VERSION_CODENAME":   "impish",
+		"ID":                 "ubuntu",
+		"ID_LIKE":            "debian",
+		"HOME_URL":           "https://www.ubuntu.com/",
+		"SUPPORT_URL":        "https://help.ubuntu.com/",
+		"BUG_REPORT_URL":     "https://bugs.launchpad.net/ubuntu/",
+		"PRIVACY_POLICY_URL": "https://www.ubuntu.com/legal/terms-and-policies/privacy-policy",
+		"UBUNTU_CODENAME":    "impish",
 	}
-	t.Cleanup(func() {
-		readFileFunc = originalReadFileFunc
-	})
-
-	lsbProperties := map[string]string{}
-	osReleaseProperties := map[string]string{}
 
-	distroIsDetectedBasedOnProperties(t, "yellow-dog", "Yellow Dog Linux", "4.0", lsbProperties,
+	distro := distroIsDetectedBasedOnProperties(t, "ubuntu", "Ubuntu", "21.10", lsbProperties,
 		osReleaseProperties)
+	if distro.IsLTS {
+		t.Error("Ubuntu 21.10 was detected as an LTS release")
+	}
+}
+
+func TestDiscoverUbuntuFromCodename(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":             "Ubuntu",
+		"VERSION_CODENAME": "jammy",
+		"ID":               "ubuntu",
+		"ID_LIKE":          "debian",
+		"UBUNTU_CODENAME":  "jammy",
+	}
+
+	distro := distroIsDetectedBasedOnProperties(t, "ubuntu", "Ubuntu", "22.04", lsbProperties,
+		osReleaseProperties)
+	if !distro.IsLTS {
+		t.Error("Ubuntu 22.04 was not detected as an LTS release")
+	}
+}
+
+func TestUbuntuLTSFromVersion(t *testing.T) {
+	tests := map[string]bool{"22.04": true, "23.04": false, "21.10": false, "24.04": true, "": false}
+
+	for version, expected := range tests {
+		lts := isUbuntuLTS(map[string]string{"DISTRIB_RELEASE": version}, map[string]string{})
+		if lts != expected {
+			t.Errorf("LTS status of Ubuntu (%s) was not detected correctly. Expected (%t) was (%t).", version,
+				expected, lts)
+		}
+	}
+}
+
+func TestDiscoverYellowDog(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/yellowdog-release"}) {
+			return true, "Yellow Dog Linux release 4.0 (Orion)\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "yellow-dog", "Yellow Dog Linux", "4.0", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverYocto(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"ID":               "poky",
+		"NAME":             "Poky (Yocto Project Reference Distro)",
+		"VERSION":          "4.0.13 (kirkstone)",
+		"VERSION_ID":       "4.0.13",
+		"VERSION_CODENAME": "kirkstone",
+		"PRETTY_NAME":      "Poky (Yocto Project Reference Distro) 4.0.13 (kirkstone)",
+		"BUILD_ID":         "20231018053806",
+		"DISTRO_CODENAME":  "kirkstone",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "poky", "Poky (Yocto Project Reference Distro)", "4.0.13",
+		lsbProperties, osReleaseProperties)
+}
+
+func TestDiscoverYoctoCustomDistroId(t *testing.T) {
+	originalYoctoDistroIds := YoctoDistroIds
+	YoctoDistroIds = append([]string{}, YoctoDistroIds...)
+	YoctoDistroIds = append(YoctoDistroIds, "acme-iot")
+	t.Cleanup(func() {
+		YoctoDistroIds = originalYoctoDistroIds
+	})
+
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"ID":          "acme-iot",
+		"NAME":        "ACME IoT Linux",
+		"VERSION":     "2.1",
+		"VERSION_ID":  "2.1",
+		"PRETTY_NAME": "ACME IoT Linux 2.1",
+		"BUILD_ID":    "20240102",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "acme-iot", "ACME IoT Linux", "2.1", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDiscoverZenwalk(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/zenwalk-version"}) {
+			return true, "Zenwalk Linux 7.0\n"
+		} else if reflect.DeepEqual(filePaths, []string{"/etc/slackware-version"}) {
+			return true, "Slackware 13.37.0\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "zenwalk", "Zenwalk", "7.0", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDetectAllOracleImpersonatingRHEL(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release"}) {
+			return true, "Red Hat Enterprise Linux Server release 7.9 (Maipo)\n"
+		}
+		if reflect.DeepEqual(filePaths, []string{"/etc/oracle-release"}) {
+			return true, "Oracle Linux Server release 7.9\n"
+		}
+
+		return false, ""
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":       "Red Hat Enterprise Linux Server",
+		"ID":         "rhel",
+		"VERSION_ID": "7.9",
+	}
+
+	matches := DetectAll(lsbProperties, osReleaseProperties)
+
+	matchedIds := map[string]bool{}
+	for _, distro := range matches {
+		matchedIds[distro.ID] = true
+	}
+	if !matchedIds["ol"] {
+		t.Errorf("Oracle Linux was not among the detected distros: %v", matches)
+	}
+	if !matchedIds["rhel"] {
+		t.Errorf("Red Hat Enterprise Linux was not among the detected distros: %v", matches)
+	}
+}
+
+func TestReportedIDOracleImpersonatingRHEL(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/redhat-release"}) {
+			return true, "Red Hat Enterprise Linux Server release 7.9 (Maipo)\n"
+		}
+		if reflect.DeepEqual(filePaths, []string{"/etc/oracle-release"}) {
+			return true, "Oracle Linux Server release 7.9\n"
+		}
+
+		return false, ""
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":       "Red Hat Enterprise Linux Server",
+		"ID":         "rhel",
+		"VERSION_ID": "7.9",
+	}
+
+	distro := distroIsDetectedBasedOnProperties(t, "ol", "Oracle Linux", "7.9", lsbProperties,
+		osReleaseProperties)
+	if distro.ReportedID != "rhel" {
+		t.Errorf("reported id was not recorded correctly. Expected (rhel) was (%s).", distro.ReportedID)
+	}
+}
+
+func TestReportedIDEmptyWhenNotImpersonating(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":       "Ubuntu",
+		"ID":         "ubuntu",
+		"VERSION_ID": "20.04",
+	}
+
+	distro := distroIsDetectedBasedOnProperties(t, "ubuntu", "Ubuntu", "20.04", lsbProperties,
+		osReleaseProperties)
+	if distro.ReportedID != "" {
+		t.Errorf("reported id was recorded for a distro that reports its own id: %s", distro.ReportedID)
+	}
+}
+
+func TestBestGuessFromKernelVersion(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/proc/version"}) {
+			return true, "Linux version 6.1.0-13-amd64 (debian-kernel@lists.debian.org) (gcc-12 (Debian 12.2.0-14) 12.2.0, GNU ld (GNU Binutils for Debian) 2.40) #1 SMP PREEMPT_DYNAMIC Debian 6.1.55-1 (2023-09-29)\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "debian", "Debian GNU/Linux", "unknown", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestBestGuessFromYumReposWithEPEL(t *testing.T) {
+	root := t.TempDir()
+	epelRepo := "[epel]\nname=Extra Packages for Enterprise Linux 9 - $basearch\n" +
+		"metalink=https://mirrors.fedoraproject.org/metalink?repo=epel-9&arch=$basearch&infra=$infra&content=$contentdir\n" +
+		"gpgcheck=1\nenabled=1\n"
+	rockyRepo := "[baseos]\nname=Rocky Linux $releasever - BaseOS\n" +
+		"mirrorlist=https://mirrors.rockylinux.org/mirrorlist?arch=$basearch&repo=BaseOS-$releasever\n" +
+		"gpgcheck=1\nenabled=1\n"
+	writeTestFile(t, root, "/etc/yum.repos.d/epel.repo", epelRepo)
+	writeTestFile(t, root, "/etc/yum.repos.d/rocky.repo", rockyRepo)
+	overrideReadFile(t, map[string]string{
+		"/etc/yum.repos.d/epel.repo":  epelRepo,
+		"/etc/yum.repos.d/rocky.repo": rockyRepo,
+	})
+
+	distro := (&Detector{Root: root}).discoverDistroFromProperties(map[string]string{}, map[string]string{})
+	if distro.ID != "rocky" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (rocky) was (%s).", distro.ID)
+	}
+}
+
+func TestBestGuessFromYumRepos(t *testing.T) {
+	root := t.TempDir()
+	rockyRepo := "[baseos]\nname=Rocky Linux $releasever - BaseOS\n" +
+		"mirrorlist=https://mirrors.rockylinux.org/mirrorlist?arch=$basearch&repo=BaseOS-$releasever\n" +
+		"#baseurl=http://dl.rockylinux.org/$contentdir/$releasever/BaseOS/$basearch/os/\n" +
+		"gpgcheck=1\nenabled=1\n"
+	writeTestFile(t, root, "/etc/yum.repos.d/rocky.repo", rockyRepo)
+
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/yum.repos.d/rocky.repo"}) {
+			return true, rockyRepo
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+
+	distro := (&Detector{Root: root}).discoverDistroFromProperties(map[string]string{}, map[string]string{})
+	if distro.ID != "rocky" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (rocky) was (%s).", distro.ID)
+	}
+	if distro.Name != "Rocky Linux" {
+		t.Errorf("Linux distro name was not detected correctly. Expected (Rocky Linux) was (%s).", distro.Name)
+	}
+}
+
+func TestBestGuessFromRedhatSupportProduct(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":                           "Linux",
+		"ID":                             "linux",
+		"VERSION_ID":                     "7",
+		"REDHAT_SUPPORT_PRODUCT":         "centos",
+		"REDHAT_SUPPORT_PRODUCT_VERSION": "7",
+	}
+
+	distro := distroIsDetectedBasedOnProperties(t, "centos", "CentOS Linux", "7", lsbProperties,
+		osReleaseProperties)
+	if !distro.IsRHELCompatible() {
+		t.Error("CentOS was not RHEL compatible")
+	}
+	if !distro.UsesRPM() {
+		t.Error("CentOS does not use RPM")
+	}
+}
+
+func TestBestGuessFromUnknownRedhatRelease(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/redhat-release": "Frobozz Linux release 2.3 (Zork)\n",
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "frobozz", "Frobozz Linux", "2.3", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestBestGuessFromSolarisRelease(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/etc/release"}) {
+			return true, "                       SmartOS x86_64\n" +
+				"              Copyright 2010 Joyent, Inc.  All Rights Reserved.\n" +
+				"              Use is subject to license terms.\n" +
+				"                   See joyent_20161108T160947Z for assembly date and time.\n"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "unknown", "SmartOS x86_64", "unknown", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestBestGuessFromLsbDescription(t *testing.T) {
+	lsbProperties := map[string]string{
+		"DISTRIB_DESCRIPTION": "Respin Linux 14.04 LTS",
+	}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "unknown", "Respin Linux 14.04 LTS", "unknown", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestDistroNamesMatchDistroTests(t *testing.T) {
+	// The distros that aren't detected from their os-release ID alone
+	lsbReleases := map[string]map[string]string{
+		"chromeos":   {"CHROMEOS_RELEASE_NAME": "Chrome OS", "CHROMEOS_RELEASE_VERSION": "15359.58.0"},
+		"clonezilla": {"DISTRIB_ID": "Clonezilla", "DISTRIB_RELEASE": "3.1.2"},
+	}
+	releaseFiles := map[string]map[string]string{
+		"android":    {"/system/build.prop": "ro.build.version.release=13\n"},
+		"centos":     {"/etc/centos-release": "CentOS Linux release 7.9.2009 (Core)\n"},
+		"clearos":    {"/etc/clearos-release": "ClearOS release 7.9.1 (Final)\n"},
+		"crux":       {"/usr/bin/crux": "echo \"CRUX version 3.7\"\n"},
+		"mandriva":   {"/etc/mandriva-release": "Mandriva Linux release 2011.0 (turtle) for x86_64\n"},
+		"nethserver": {"/etc/nethserver-release": "NethServer release 7.9.2009 (final)\n"},
+		"oes":        {"/etc/novell-release": "Novell Open Enterprise Server 2018 (x86_64)\nVERSION = 2018\n"},
+		"salix":      {"/etc/salix-version": "Salix 15.0\n"},
+		"scientific": {"/etc/sl-release": "Scientific Linux release 7.9 (Nitrogen)\n"},
+		"sourcemage": {"/etc/sourcemage-release": "Source Mage GNU/Linux x86_64-pc-linux-gnu\nInstalled from tarball using chroot image (Grimoire 0.62-stable) on Thu May 17 11:48:15 UTC 2012\n"},
+		"yellow-dog": {"/etc/yellowdog-release": "Yellow Dog Linux release 6.2 (Pyxis)\n"},
+		"zenwalk":    {"/etc/zenwalk-version": "Zenwalk 15.0\n"},
+	}
+
+	originalReadBinaryFileFunc := readBinaryFileFunc
+	t.Cleanup(func() {
+		readBinaryFileFunc = originalReadBinaryFileFunc
+	})
+
+	for id, name := range distroNames {
+		overrideReadFile(t, releaseFiles[id])
+		readBinaryFileFunc = func(_ *Detector, filePaths []string) (io.ReadCloser, string, error) {
+			if id == "busybox" && reflect.DeepEqual(filePaths, []string{"/bin/true"}) {
+				reader, err := os.Open("test-binary-busybox-amd64-true")
+				return reader, "/bin/true", err
+			}
+
+			return nil, "", os.ErrNotExist
+		}
+
+		osReleaseProperties := map[string]string{"ID": id, "VERSION_ID": "1"}
+		if releaseFiles[id] != nil || lsbReleases[id] != nil || id == "busybox" {
+			osReleaseProperties = map[string]string{}
+		}
+
+		detector := NewDetector()
+		distro := detector.discoverDistroFromProperties(lsbReleases[id], osReleaseProperties)
+		if detector.guessed || distro.ID != id {
+			t.Errorf("no distro test detected the distro (%s)", id)
+			continue
+		}
+		if distro.Name != name {
+			t.Errorf("the name of the distro (%s) doesn't match its distro test. Expected (%s) was (%s).", id,
+				distro.Name, name)
+		}
+	}
+}
+
+func TestBestGuessNameFromKnownID(t *testing.T) {
+	originalDistroTestTable := distroTestTable
+	distroTestTable = nil
+	for _, distroTest := range originalDistroTestTable {
+		if distroTest.name != "IsAmazonLinux" {
+			distroTestTable = append(distroTestTable, distroTest)
+		}
+	}
+	t.Cleanup(func() {
+		distroTestTable = originalDistroTestTable
+	})
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"ID":         "amzn",
+		"VERSION_ID": "2023",
+	}
+
+	distroIsDetectedBasedOnProperties(t, "amzn", "Amazon Linux", "2023", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestBestGuessFromEFIVendor(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/boot/efi/EFI/BOOT/BOOTX64.EFI", "")
+	writeTestFile(t, root, "/boot/efi/EFI/rocky/shimx64.efi", "")
+
+	distro := (&Detector{Root: root}).discoverDistroFromProperties(map[string]string{}, map[string]string{})
+	if distro.ID != "rocky" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (rocky) was (%s).", distro.ID)
+	}
+	if distro.Name != "Rocky Linux" {
+		t.Errorf("Linux distro name was not detected correctly. Expected (Rocky Linux) was (%s).", distro.Name)
+	}
+}
+
+func TestBestGuessWithoutKernelVersion(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{}
+
+	distroIsDetectedBasedOnProperties(t, "unknown", "Unknown", "unknown", lsbProperties,
+		osReleaseProperties)
+}
+
+func TestBestGuessPackageManagerFromRPMDatabase(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/var/lib/rpm/rpmdb.sqlite", "")
+
+	distro := (&Detector{Root: root}).DiscoverDistro()
+	if distro.ID != "unknown" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (unknown) was (%s).", distro.ID)
+	}
+	if distro.PackageManager() != "rpm" {
+		t.Errorf("package manager was not detected correctly. Expected (rpm) was (%s).", distro.PackageManager())
+	}
+}
+
+func TestBestGuessPackageManagerFromDpkgStatus(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/var/lib/dpkg/status", "Package: base-files\nStatus: install ok installed\n")
+
+	distro := (&Detector{Root: root}).DiscoverDistro()
+	if distro.ID != "unknown" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (unknown) was (%s).", distro.ID)
+	}
+	if distro.PackageManager() != "dpkg" {
+		t.Errorf("package manager was not detected correctly. Expected (dpkg) was (%s).", distro.PackageManager())
+	}
+}
+
+func TestDetectedPackageManagerDpkg(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/usr/bin/dpkg", "")
+
+	packageManager := WithRoot(root).DetectedPackageManager()
+	if packageManager != "dpkg" {
+		t.Errorf("package manager was not detected correctly. Expected (dpkg) was (%s).", packageManager)
+	}
+}
+
+func TestDetectedPackageManagerApk(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/sbin/apk", "")
+
+	packageManager := WithRoot(root).DetectedPackageManager()
+	if packageManager != "apk" {
+		t.Errorf("package manager was not detected correctly. Expected (apk) was (%s).", packageManager)
+	}
+}
+
+func TestDetectedPackageManagerNone(t *testing.T) {
+	packageManager := WithRoot(t.TempDir()).DetectedPackageManager()
+	if packageManager != "" {
+		t.Errorf("package manager was detected without any package manager binaries: %s", packageManager)
+	}
+}
+
+func TestIDLikeOracleLinuxIsRHELCompatible(t *testing.T) {
+	distro := LinuxDistro{
+		ID:        "example",
+		OsRelease: ReleaseDetails{"ID": "example", "ID_LIKE": "ol fedora"},
+	}
+	if !distro.IsRHELCompatible() {
+		t.Error("distro with ID_LIKE of ol was not RHEL compatible")
+	}
+	if !distro.IsRedhatCompatible() {
+		t.Error("distro with ID_LIKE of ol was not Red Hat compatible")
+	}
+
+	distro.OsRelease["ID_LIKE"] = "ol"
+	if !distro.IsRHELCompatible() {
+		t.Error("distro with ID_LIKE of ol was not RHEL compatible")
+	}
+}
+
+func TestIDLikeAlmaLinuxIsRHELCompatible(t *testing.T) {
+	distro := LinuxDistro{
+		ID:        "example",
+		OsRelease: ReleaseDetails{"ID": "example", "ID_LIKE": "almalinux"},
+	}
+	if !distro.IsRHELCompatible() {
+		t.Error("distro with ID_LIKE of almalinux was not RHEL compatible")
+	}
+	if !distro.IsRedhatCompatible() {
+		t.Error("distro with ID_LIKE of almalinux was not Red Hat compatible")
+	}
+	if !distro.UsesRPM() {
+		t.Error("distro with ID_LIKE of almalinux does not use rpm")
+	}
+}
+
+func TestRegisterAlias(t *testing.T) {
+	t.Cleanup(func() {
+		distroAliasesLock.Lock()
+		defer distroAliasesLock.Unlock()
+		delete(distroAliases, "acmecorp-linux")
+		delete(distroAliases, "acmecorp-desktop")
+	})
+
+	distro := LinuxDistro{
+		ID:        "acmecorp-linux",
+		OsRelease: ReleaseDetails{"ID": "acmecorp-linux"},
+	}
+	if distro.UsesRPM() {
+		t.Error("distro without a registered alias unexpectedly uses rpm")
+	}
+
+	RegisterAlias("acmecorp-linux", "rhel")
+	if !distro.UsesRPM() {
+		t.Error("distro with a registered rhel alias does not use rpm")
+	}
+	if !distro.IsRHELCompatible() {
+		t.Error("distro with a registered rhel alias was not RHEL compatible")
+	}
+	if distro.PackageManager() != "rpm" {
+		t.Errorf("package manager was not detected correctly. Expected (rpm) was (%s).", distro.PackageManager())
+	}
+
+	RegisterAlias("acmecorp-desktop", "ubuntu")
+	likeDistro := LinuxDistro{
+		ID:        "acme-kiosk",
+		OsRelease: ReleaseDetails{"ID": "acme-kiosk", "ID_LIKE": "acmecorp-desktop"},
+	}
+	if likeDistro.PackageManager() != "dpkg" {
+		t.Errorf("package manager was not detected correctly. Expected (dpkg) was (%s).", likeDistro.PackageManager())
+	}
+}
+
+func TestEditionWithoutVariant(t *testing.T) {
+	osReleaseProperties := map[string]string{
+		"NAME":       "Arch Linux",
+		"ID":         "arch",
+		"BUILD_ID":   "rolling",
+		"VERSION_ID": "20210301.0.16414",
+	}
+
+	distro := distroIsDetectedBasedOnProperties(t, "arch", "Arch Linux", "rolling", map[string]string{},
+		osReleaseProperties)
+	if distro.Edition != "" {
+		t.Errorf("Linux distro edition was unexpectedly detected: %s", distro.Edition)
+	}
+}
+
+func TestEditionFromUbuntuDesktopPackage(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Ubuntu\"\nID=ubuntu\nID_LIKE=debian\nVERSION_ID=\"22.04\"\n")
+	writeTestFile(t, root, "/var/lib/dpkg/info/ubuntu-desktop-minimal.list", "/.\n/usr\n")
+
+	distro := WithRoot(root).DiscoverDistro()
+	if distro.Edition != "desktop" {
+		t.Errorf("Linux distro edition was not detected correctly. Expected (desktop) was (%s).", distro.Edition)
+	}
+}
+
+func TestNormalizeIDs(t *testing.T) {
+	osReleaseProperties := map[string]string{
+		"NAME":       "Oracle Linux Server",
+		"ID":         "ol",
+		"ID_LIKE":    "fedora",
+		"VERSION_ID": "8.9",
+	}
+
+	distro := (&Detector{NormalizeIDs: true}).discoverDistroFromProperties(map[string]string{}, osReleaseProperties)
+	if distro.ID != "oracle" {
+		t.Errorf("Linux distro id was not normalized. Expected (oracle) was (%s).", distro.ID)
+	}
+	if distro.ReportedID != "ol" {
+		t.Errorf("reported id was not preserved. Expected (ol) was (%s).", distro.ReportedID)
+	}
+	if distro.Vendor != "oracle" {
+		t.Errorf("Linux distro vendor was not detected correctly. Expected (oracle) was (%s).", distro.Vendor)
+	}
+	if _, ok := distro.EOLDate(); !ok {
+		t.Error("end of life date was not found for the normalized distro")
+	}
+	if !distro.IsRHELCompatible() {
+		t.Error("the normalized distro was not detected as RHEL compatible")
+	}
+	if !distro.IsRedhatCompatible() {
+		t.Error("the normalized distro was not detected as Red Hat compatible")
+	}
+	if distro.PackageManager() != "rpm" {
+		t.Errorf("package manager was not detected correctly. Expected (rpm) was (%s).", distro.PackageManager())
+	}
+
+	distro = NewDetector().discoverDistroFromProperties(map[string]string{}, osReleaseProperties)
+	if distro.ID != "ol" {
+		t.Errorf("Linux distro id was normalized by default. Expected (ol) was (%s).", distro.ID)
+	}
+}
+
+func TestVendor(t *testing.T) {
+	tests := []struct {
+		name                string
+		osReleaseProperties map[string]string
+		vendor              string
+	}{
+		{
+			name:                "Oracle Linux",
+			osReleaseProperties: map[string]string{"NAME": "Oracle Linux Server", "ID": "ol", "VERSION_ID": "8.2"},
+			vendor:              "oracle",
+		},
+		{
+			name:                "Red Hat Enterprise Linux",
+			osReleaseProperties: map[string]string{"NAME": "Red Hat Enterprise Linux", "ID": "rhel", "VERSION_ID": "9.2"},
+			vendor:              "redhat",
+		},
+		{
+			name:                "Ubuntu",
+			osReleaseProperties: map[string]string{"NAME": "Ubuntu", "ID": "ubuntu", "VERSION_ID": "20.04"},
+			vendor:              "canonical",
+		},
+		{
+			name:                "Amazon Linux",
+			osReleaseProperties: map[string]string{"NAME": "Amazon Linux", "ID": "amzn", "VERSION_ID": "2"},
+			vendor:              "amazon",
+		},
+		{
+			name:                "Unknown",
+			osReleaseProperties: map[string]string{"NAME": "Example Linux", "ID": "example", "VERSION_ID": "1"},
+			vendor:              "",
+		},
+	}
+
+	for _, test := range tests {
+		distro := NewDetector().discoverDistroFromProperties(map[string]string{}, test.osReleaseProperties)
+		if distro.Vendor != test.vendor {
+			t.Errorf("%s vendor was not detected correctly. Expected (%s) was (%s).", test.name, test.vendor,
+				distro.Vendor)
+		}
+	}
+}
+
+func TestIsImmutable(t *testing.T) {
+	tests := []struct {
+		name      string
+		distro    LinuxDistro
+		immutable bool
+	}{
+		{
+			name:      "Fedora Silverblue",
+			distro:    LinuxDistro{ID: "fedora", OsRelease: ReleaseDetails{"ID": "fedora", "VARIANT_ID": "silverblue"}},
+			immutable: true,
+		},
+		{
+			name:      "Flatcar",
+			distro:    LinuxDistro{ID: "flatcar", OsRelease: ReleaseDetails{"ID": "flatcar"}},
+			immutable: true,
+		},
+		{
+			name:      "NixOS",
+			distro:    LinuxDistro{ID: "nixos", OsRelease: ReleaseDetails{"ID": "nixos"}},
+			immutable: true,
+		},
+		{
+			name:      "OSTree booted",
+			distro:    LinuxDistro{ID: "centos", OsRelease: ReleaseDetails{"ID": "centos"}, ostreeBooted: true},
+			immutable: true,
+		},
+		{
+			name:      "Fedora Workstation",
+			distro:    LinuxDistro{ID: "fedora", OsRelease: ReleaseDetails{"ID": "fedora", "VARIANT_ID": "workstation"}},
+			immutable: false,
+		},
+		{
+			name:      "Ubuntu",
+			distro:    LinuxDistro{ID: "ubuntu", OsRelease: ReleaseDetails{"ID": "ubuntu"}},
+			immutable: false,
+		},
+	}
+
+	for _, test := range tests {
+		if test.distro.IsImmutable() != test.immutable {
+			t.Errorf("%s immutability was not detected correctly. Expected (%t) was (%t).", test.name,
+				test.immutable, test.distro.IsImmutable())
+		}
+	}
+}
+
+func TestIsImmutableOSTreeRoot(t *testing.T) {
+	ostreeRoot := t.TempDir()
+	writeTestFile(t, ostreeRoot, "/etc/os-release", "NAME=\"CentOS Stream\"\nID=\"centos\"\nVERSION_ID=\"9\"\n")
+	writeTestFile(t, ostreeRoot, "/run/ostree-booted", "")
+	plainRoot := t.TempDir()
+	writeTestFile(t, plainRoot, "/etc/os-release", "NAME=\"CentOS Stream\"\nID=\"centos\"\nVERSION_ID=\"9\"\n")
+
+	// The markers are checked under the root of the detector rather than FileSystemRoot
+	originalFileSystemRoot := FileSystemRoot
+	t.Cleanup(func() {
+		FileSystemRoot = originalFileSystemRoot
+	})
+	FileSystemRoot = ostreeRoot
+
+	if distro := WithRoot(plainRoot).DiscoverDistro(); distro.IsImmutable() {
+		t.Error("distro without OSTree markers was detected as immutable")
+	}
+	if distro := WithRoot(ostreeRoot).DiscoverDistro(); !distro.IsImmutable() {
+		t.Error("distro with OSTree markers was not detected as immutable")
+	}
+}
+
+func TestPackageManagerFromDistroId(t *testing.T) {
+	distro := LinuxDistro{
+		ID:        "linuxmint",
+		OsRelease: ReleaseDetails{"ID": "linuxmint", "ID_LIKE": "ubuntu"},
+	}
+	if distro.PackageManager() != "dpkg" {
+		t.Errorf("package manager was not detected correctly. Expected (dpkg) was (%s).", distro.PackageManager())
+	}
+}
+
+func TestOracleReleaseFileIsReadOnce(t *testing.T) {
+	tests := []struct {
+		name                string
+		oracleRelease       string
+		osReleaseProperties map[string]string
+		id                  string
+	}{
+		{
+			name:          "Oracle Linux",
+			oracleRelease: "Oracle Linux Server release 7.9\n",
+			osReleaseProperties: map[string]string{
+				"NAME": "Red Hat Enterprise Linux Server",
+				"ID":   "rhel",
+			},
+			id: "ol",
+		},
+		{
+			name: "Red Hat Enterprise Linux",
+			osReleaseProperties: map[string]string{
+				"NAME":       "Red Hat Enterprise Linux Server",
+				"ID":         "rhel",
+				"VERSION_ID": "7.9",
+			},
+			id: "rhel",
+		},
+	}
+
+	originalReadFileFunc := readFileFunc
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+
+	for _, test := range tests {
+		reads := 0
+		readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+			if reflect.DeepEqual(filePaths, []string{"/etc/oracle-release"}) {
+				reads++
+				return test.oracleRelease != "", test.oracleRelease
+			}
+
+			return false, ""
+		}
+
+		distro := NewDetector().discoverDistroFromProperties(map[string]string{}, test.osReleaseProperties)
+		if distro.ID != test.id {
+			t.Errorf("%s: Linux distro id was not detected correctly. Expected (%s) was (%s).", test.name,
+				test.id, distro.ID)
+		}
+		if reads != 1 {
+			t.Errorf("%s: /etc/oracle-release was read %d times", test.name, reads)
+		}
+	}
+}
+
+func TestDerivativeReleaseFilesAreReadOnce(t *testing.T) {
+	tests := []struct {
+		name                string
+		files               map[string]string
+		osReleaseProperties map[string]string
+		id                  string
+		readOnce            []string
+	}{
+		{
+			name:  "Red Hat Enterprise Linux",
+			files: map[string]string{"/etc/redhat-release": "Red Hat Enterprise Linux Server release 7.9 (Maipo)\n"},
+			osReleaseProperties: map[string]string{
+				"ID":         "rhel",
+				"VERSION_ID": "7.9",
+			},
+			id:       "rhel",
+			readOnce: []string{"/etc/oracle-release", "/etc/nethserver-release", "/etc/clearos-release"},
+		},
+		{
+			name:  "Debian",
+			files: map[string]string{"/etc/debian_version": "11.6\n"},
+			osReleaseProperties: map[string]string{
+				"ID":         "debian",
+				"VERSION_ID": "11",
+			},
+			id:       "debian",
+			readOnce: []string{"/etc/mx-version", "/etc/drbl/drbl.conf", "/etc/rpi-issue"},
+		},
+		{
+			name:  "Slackware",
+			files: map[string]string{"/etc/slackware-version": "Slackware 15.0\n"},
+			osReleaseProperties: map[string]string{
+				"ID":         "slackware",
+				"VERSION_ID": "15.0",
+			},
+			id:       "slackware",
+			readOnce: []string{"/etc/salix-version", "/etc/zenwalk-version"},
+		},
+	}
+
+	originalReadFileFunc := readFileFunc
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+
+	for _, test := range tests {
+		reads := map[string]int{}
+		readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+			reads[filePaths[0]]++
+			contents, ok := test.files[filePaths[0]]
+			return ok, contents
+		}
+
+		distro := NewDetector().discoverDistroFromProperties(map[string]string{}, test.osReleaseProperties)
+		if distro.ID != test.id {
+			t.Errorf("%s: Linux distro id was not detected correctly. Expected (%s) was (%s).", test.name,
+				test.id, distro.ID)
+		}
+		for _, filePath := range test.readOnce {
+			if reads[filePath] != 1 {
+				t.Errorf("%s: %s was read %d times", test.name, filePath, reads[filePath])
+			}
+		}
+	}
+}
+
+func TestRedhatFamilyTestsRuleOutOracleLinux(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/oracle-release": "Oracle Linux Server release 7.9\n",
+		"/etc/redhat-release": "Red Hat Enterprise Linux Server release 7.9 (Maipo)\n",
+		"/etc/centos-release": "CentOS Linux release 7.9.2009 (Core)\n",
+		"/etc/sl-release":     "Scientific Linux release 7.9 (Nitrogen)\n",
+		"/etc/fedora-release": "Fedora release 33 (Thirty Three)\n",
+	})
+
+	if getFunctionName(DistroTests[0]) != getFunctionName(IsOracleLinux) {
+		t.Errorf("the Oracle Linux test is not the first of DistroTests: %s", getFunctionName(DistroTests[0]))
+	}
+
+	for _, distroTest := range []func(ReleaseDetails, ReleaseDetails) (bool, LinuxDistro){
+		IsCentOS, IsRHEL, IsFedora, IsScientificLinux,
+	} {
+		_, distro := distroTest(ReleaseDetails{}, ReleaseDetails{"ID": "rhel"})
+		if distro.ID != "ol" {
+			t.Errorf("%s did not rule out Oracle Linux. Expected (ol) was (%s).", getFunctionName(distroTest),
+				distro.ID)
+		}
+	}
+}
+
+func TestDiscoverDistros(t *testing.T) {
+	originalBatchConcurrency := BatchConcurrency
+	BatchConcurrency = 2
+	t.Cleanup(func() {
+		BatchConcurrency = originalBatchConcurrency
+	})
+
+	alpineRoot := t.TempDir()
+	writeTestFile(t, alpineRoot, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\n")
+	ubuntuRoot := t.TempDir()
+	writeTestFile(t, ubuntuRoot, "/etc/lsb-release", "DISTRIB_ID=Ubuntu\nDISTRIB_RELEASE=20.04\n")
+	writeTestFile(t, ubuntuRoot, "/etc/os-release", "NAME=\"Ubuntu\"\nID=ubuntu\nVERSION_ID=\"20.04\"\n")
+	fedoraRoot := t.TempDir()
+	writeTestFile(t, fedoraRoot, "/etc/os-release", "NAME=Fedora\nID=fedora\nVERSION_ID=33\n")
+	archRoot := t.TempDir()
+	writeTestFile(t, archRoot, "/etc/os-release", "NAME=\"Arch Linux\"\nID=arch\nBUILD_ID=rolling\n")
+
+	roots := []string{alpineRoot, ubuntuRoot, fedoraRoot, archRoot}
+	expectedIds := []string{"alpine", "ubuntu", "fedora", "arch"}
+
+	results := DiscoverDistros(roots)
+	if len(results) != len(roots) {
+		t.Fatalf("expected %d results, but there were %d", len(roots), len(results))
+	}
+	for i, result := range results {
+		if result.Root != roots[i] {
+			t.Errorf("result %d has the root (%s) but expected (%s)", i, result.Root, roots[i])
+		}
+		if result.Distro.ID != expectedIds[i] {
+			t.Errorf("Linux distro id for root (%s) was not detected correctly. Expected (%s) was (%s).",
+				result.Root, expectedIds[i], result.Distro.ID)
+		}
+	}
+}
+
+func TestDiscoverDistrosUnder(t *testing.T) {
+	parent := t.TempDir()
+	writeTestFile(t, parent, "/ubuntu/etc/lsb-release", "DISTRIB_ID=Ubuntu\nDISTRIB_RELEASE=20.04\n")
+	writeTestFile(t, parent, "/ubuntu/etc/os-release", "NAME=\"Ubuntu\"\nID=ubuntu\nVERSION_ID=\"20.04\"\n")
+	writeTestFile(t, parent, "/fedora/usr/lib/os-release", "NAME=Fedora\nID=fedora\nVERSION_ID=33\n")
+	writeTestFile(t, parent, "/boot/grub/grub.cfg", "set default=0\n")
+
+	expectedIds := map[string]string{"ubuntu": "ubuntu", "fedora": "fedora"}
+
+	for _, subdirs := range [][]string{nil, {"ubuntu", "fedora"}} {
+		distros := DiscoverDistrosUnder(parent, subdirs)
+		if len(distros) != len(expectedIds) {
+			t.Errorf("expected %d distros, but there were %d: %v", len(expectedIds), len(distros), distros)
+		}
+		for subdir, expectedId := range expectedIds {
+			if distros[subdir].ID != expectedId {
+				t.Errorf("Linux distro id for subdirectory (%s) was not detected correctly. Expected (%s) was (%s).",
+					subdir, expectedId, distros[subdir].ID)
+			}
+		}
+	}
+}
+
+func TestWriteDistrosJSON(t *testing.T) {
+	originalBatchConcurrency := BatchConcurrency
+	BatchConcurrency = 4
+	t.Cleanup(func() {
+		BatchConcurrency = originalBatchConcurrency
+	})
+
+	roots := make([]string, 100)
+	for i := range roots {
+		roots[i] = t.TempDir()
+		writeTestFile(t, roots[i], "/etc/os-release", fmt.Sprintf("NAME=Fedora\nID=fedora\nVERSION_ID=%d\n", i))
+	}
+
+	output := &countingWriter{}
+	if err := WriteDistrosJSON(output, roots); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []RootDistro
+	if err := json.Unmarshal(output.Bytes(), &results); err != nil {
+		t.Fatalf("output was not valid JSON: %v\n%s", err, output.String())
+	}
+	if len(results) != len(roots) {
+		t.Fatalf("expected %d results, but there were %d", len(roots), len(results))
+	}
+	for _, result := range results {
+		expectedVersion := strconv.Itoa(indexOf(roots, result.Root))
+		if result.Distro.Version != expectedVersion {
+			t.Errorf("Linux distro version for root (%s) was not detected correctly. Expected (%s) was (%s).",
+				result.Root, expectedVersion, result.Distro.Version)
+		}
+	}
+	// Each result is written separately rather than all at once when the detection is complete
+	if output.writes < len(roots) {
+		t.Errorf("output was written in %d writes, expected at least %d", output.writes, len(roots))
+	}
+}
+
+func TestJSONArrayEncoderEmpty(t *testing.T) {
+	var output bytes.Buffer
+	if err := NewJSONArrayEncoder(&output).Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if output.String() != "[]"+env.LineBreak {
+		t.Errorf("unexpected output for an empty array: %q", output.String())
+	}
+}
+
+func TestDiscoverDistroContextCancelled(t *testing.T) {
+	unblock := make(chan struct{})
+	originalReadBinaryFileFunc := readBinaryFileFunc
+	readBinaryFileFunc = func(_ *Detector, filePaths []string) (io.ReadCloser, string, error) {
+		return &blockingReader{unblock: unblock}, filePaths[0], nil
+	}
+	t.Cleanup(func() {
+		close(unblock)
+		readBinaryFileFunc = originalReadBinaryFileFunc
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := (&Detector{Root: t.TempDir()}).DiscoverDistroContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a context cancellation error, but was: %v", err)
+	}
+}
+
+func TestDiscoverDistroContextCompleted(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\n")
+
+	distro, err := (&Detector{Root: root}).DiscoverDistroContext(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+	if distro.ID != "alpine" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (alpine) was (%s).", distro.ID)
+	}
+}
+
+func TestDiscoverDistroContextInvalidRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "nonexistent")
+
+	distro, err := (&Detector{Root: root}).DiscoverDistroContext(context.Background())
+	if !errors.Is(err, ErrInvalidRoot) {
+		t.Errorf("expected ErrInvalidRoot for a nonexistent root, but was: %v", err)
+	}
+	if distro.ID != "unknown" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (unknown) was (%s).", distro.ID)
+	}
+}
+
+// TestDiscoverFromOsReleaseAlone verifies that distros are identified from os-release alone, as they
+// are in systemd container and machine images that have no lsb-release and an otherwise empty /etc.
+func TestDiscoverFromOsReleaseAlone(t *testing.T) {
+	originalReadBinaryFileFunc := readBinaryFileFunc
+	readBinaryFileFunc = func(_ *Detector, filePaths []string) (io.ReadCloser, string, error) {
+		return nil, "", errors.New("file-based fallbacks are disabled")
+	}
+	t.Cleanup(func() {
+		readBinaryFileFunc = originalReadBinaryFileFunc
+	})
+
+	tests := []struct {
+		osRelease string
+		id        string
+		name      string
+		version   string
+	}{
+		{
+			osRelease: "NAME=\"Fedora Linux\"\nVERSION=\"39 (Container Image)\"\nID=fedora\nVERSION_ID=39\nPRETTY_NAME=\"Fedora Linux 39 (Container Image)\"\n",
+			id:        "fedora", name: "Fedora", version: "39",
+		},
+		{
+			osRelease: "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.19.1\nPRETTY_NAME=\"Alpine Linux v3.19\"\n",
+			id:        "alpine", name: "Alpine Linux", version: "3.19.1",
+		},
+		{
+			osRelease: "NAME=\"Arch Linux\"\nPRETTY_NAME=\"Arch Linux\"\nID=arch\nBUILD_ID=rolling\n",
+			id:        "arch", name: "Arch Linux", version: "rolling",
+		},
+		{
+			osRelease: "PRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\nNAME=\"Debian GNU/Linux\"\nVERSION_ID=\"12\"\nVERSION=\"12 (bookworm)\"\nVERSION_CODENAME=bookworm\nID=debian\n",
+			id:        "debian", name: "Debian GNU/Linux", version: "12",
+		},
+		{
+			osRelease: "PRETTY_NAME=\"Ubuntu 22.04.4 LTS\"\nNAME=\"Ubuntu\"\nVERSION_ID=\"22.04\"\nVERSION=\"22.04.4 LTS (Jammy Jellyfish)\"\nVERSION_CODENAME=jammy\nID=ubuntu\nID_LIKE=debian\n",
+			id:        "ubuntu", name: "Ubuntu", version: "22.04",
+		},
+		{
+			osRelease: "NAME=\"openSUSE\"\nVERSION=\"15.5\"\nID=\"opensuse\"\nID_LIKE=\"suse\"\nVERSION_ID=\"15.5\"\n",
+			id:        "opensuse", name: "openSUSE", version: "15.5",
+		},
+		{
+			osRelease: "NAME=\"Rocky Linux\"\nVERSION=\"9.3 (Blue Onyx)\"\nID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\nVERSION_ID=\"9.3\"\nPLATFORM_ID=\"platform:el9\"\n",
+			id:        "rocky", name: "Rocky Linux", version: "9.3",
+		},
+		{
+			osRelease: "NAME=\"Red Hat Enterprise Linux\"\nVERSION=\"9.3 (Plow)\"\nID=\"rhel\"\nID_LIKE=\"fedora\"\nVERSION_ID=\"9.3\"\n",
+			id:        "rhel", name: "Red Hat Enterprise Linux", version: "9.3",
+		},
+		{
+			osRelease: "NAME=\"Oracle Linux Server\"\nVERSION=\"8.9\"\nID=\"ol\"\nID_LIKE=\"fedora\"\nVERSION_ID=\"8.9\"\n",
+			id:        "ol", name: "Oracle Linux", version: "8.9",
+		},
+		{
+			osRelease: "NAME=\"Amazon Linux\"\nVERSION=\"2023\"\nID=\"amzn\"\nID_LIKE=\"fedora\"\nVERSION_ID=\"2023\"\n",
+			id:        "amzn", name: "Amazon Linux", version: "2023",
+		},
+		{
+			osRelease: "NAME=\"SLES\"\nVERSION=\"15-SP5\"\nVERSION_ID=\"15.5\"\nID=\"sles\"\nID_LIKE=\"suse\"\n",
+			id:        "sles", name: "SUSE Linux", version: "15.5",
+		},
+		{
+			osRelease: "NAME=\"Linux Mint\"\nVERSION=\"21.3 (Virginia)\"\nID=linuxmint\nID_LIKE=\"ubuntu debian\"\nVERSION_ID=\"21.3\"\nUBUNTU_CODENAME=jammy\n",
+			id:        "linuxmint", name: "Linux Mint", version: "21.3",
+		},
+		{
+			osRelease: "PRETTY_NAME=\"MX Linux 23 (libretto)\"\nNAME=\"MX Linux\"\nVERSION_ID=\"23\"\nID=mx\nID_LIKE=debian\n",
+			id:        "mx", name: "MX Linux", version: "23",
+		},
+		{
+			osRelease: "NAME=Puppy\nVERSION=\"9.5\"\nID=puppy_fossapup64\nVERSION_ID=9.5\nPRETTY_NAME=\"fossapup64 9.5\"\n",
+			id:        "puppy", name: "Puppy Linux", version: "9.5",
+		},
+		{
+			osRelease: "NAME=\"VMware Photon OS\"\nVERSION=\"5.0\"\nID=photon\nVERSION_ID=5.0\n",
+			id:        "photon", name: "VMware Photon", version: "5.0",
+		},
+	}
+
+	for _, test := range tests {
+		osReleaseProperties, err := parseOSRelease(strings.NewReader(test.osRelease))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		distro := NewDetector().discoverDistroFromProperties(ReleaseDetails{}, osReleaseProperties)
+		if distro.ID != test.id {
+			t.Errorf("Linux distro id was not detected correctly. Expected (%s) was (%s).", test.id, distro.ID)
+		}
+		if distro.Name != test.name {
+			t.Errorf("Linux distro name was not detected correctly for (%s). Expected (%s) was (%s).", test.id,
+				test.name, distro.Name)
+		}
+		if distro.Version != test.version {
+			t.Errorf("Linux distro version was not detected correctly for (%s). Expected (%s) was (%s).", test.id,
+				test.version, distro.Version)
+		}
+	}
+}
+
+func TestDiscoverDistroEWarnings(t *testing.T) {
+	result, err := (&Detector{Root: t.TempDir()}).DiscoverDistroE()
+	if !errors.Is(err, ErrDistroNotDetected) {
+		t.Errorf("expected ErrDistroNotDetected for an unknown distro, but was: %v", err)
+	}
+
+	found := false
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning, "attempting best guess") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("best guess warning was not among the warnings: %v", result.Warnings)
+	}
+	if result.Distro.ID != "unknown" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (unknown) was (%s).", result.Distro.ID)
+	}
+}
+
+func TestDiscoverDistroEInvalidRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "nonexistent")
+
+	result, err := (&Detector{Root: root}).DiscoverDistroE()
+	if !errors.Is(err, ErrInvalidRoot) {
+		t.Errorf("expected ErrInvalidRoot for a nonexistent root, but was: %v", err)
+	}
+	if result.Distro.ID != "unknown" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (unknown) was (%s).", result.Distro.ID)
+	}
+	if result.Distro.DetectedAt.IsZero() {
+		t.Error("detection time was not set for the unknown distro")
+	}
+}
+
+func TestDiscoverDistroInvalidRootLogsError(t *testing.T) {
+	var errorLines []string
+	originalLogErrorf := LogErrorf
+	LogErrorf = func(format string, args ...interface{}) {
+		errorLines = append(errorLines, fmt.Sprintf(format, args...))
+	}
+	t.Cleanup(func() {
+		LogErrorf = originalLogErrorf
+	})
+
+	root := filepath.Join(t.TempDir(), "nonexistent")
+	distro := WithRoot(root).DiscoverDistro()
+	if distro.ID != "unknown" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (unknown) was (%s).", distro.ID)
+	}
+	if len(errorLines) != 1 {
+		t.Errorf("expected a single error to be logged, but was: %v", errorLines)
+	}
+}
+
+func TestDiscoverDistroENoWarnings(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\n")
+
+	result, err := (&Detector{Root: root}).DiscoverDistroE()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(result.Warnings) > 0 {
+		t.Errorf("no warnings were expected: %v", result.Warnings)
+	}
+}
+
+func TestDebugLogsDetectorResults(t *testing.T) {
+	var debugLines []string
+	originalLogDebugf := LogDebugf
+	LogDebugf = func(format string, args ...interface{}) {
+		debugLines = append(debugLines, fmt.Sprintf(format, args...))
+	}
+	t.Cleanup(func() {
+		LogDebugf = originalLogDebugf
+	})
+
+	lsbProperties := map[string]string{
+		"DISTRIB_ID":       "Ubuntu",
+		"DISTRIB_RELEASE":  "20.04",
+		"DISTRIB_CODENAME": "focal",
+	}
+	osReleaseProperties := map[string]string{
+		"NAME":       "Ubuntu",
+		"ID":         "ubuntu",
+		"ID_LIKE":    "debian",
+		"VERSION_ID": "20.04",
+	}
+
+	detector := NewDetector()
+	detector.Debug = true
+	distro := detector.discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if distro.ID != "ubuntu" {
+		t.Fatalf("Linux distro id was not detected correctly. Expected (ubuntu) was (%s).", distro.ID)
+	}
+
+	if len(debugLines) == 0 {
+		t.Fatal("no debug output was logged")
+	}
+	for i, line := range debugLines {
+		last := i == len(debugLines)-1
+		if last && !strings.HasPrefix(line, "IsUbuntu=true") {
+			t.Errorf("last debug line did not report IsUbuntu as detected: %s", line)
+		}
+		if !last && !strings.Contains(line, "=false") {
+			t.Errorf("detector before IsUbuntu was not reported as false: %s", line)
+		}
+	}
+}
+
+func TestDebugDisabledByDefault(t *testing.T) {
+	logged := false
+	originalLogDebugf := LogDebugf
+	LogDebugf = func(format string, args ...interface{}) {
+		logged = true
+	}
+	t.Cleanup(func() {
+		LogDebugf = originalLogDebugf
+	})
+
+	NewDetector().discoverDistroFromProperties(map[string]string{}, map[string]string{"ID": "ubuntu"})
+	if logged {
+		t.Error("debug output was logged when debug was disabled")
+	}
+}
+
+func TestInspectedPathsUbuntu(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/lsb-release", "DISTRIB_ID=Ubuntu\nDISTRIB_RELEASE=20.04\nDISTRIB_CODENAME=focal\nDISTRIB_DESCRIPTION=\"Ubuntu 20.04.1 LTS\"\n")
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Ubuntu\"\nVERSION=\"20.04.1 LTS (Focal Fossa)\"\nID=ubuntu\nID_LIKE=debian\nVERSION_ID=\"20.04\"\n")
+
+	detector := &Detector{
+		Root:                 root,
+		RecordInspectedPaths: true,
+	}
+	distro := detector.DiscoverDistro()
+	if distro.ID != "ubuntu" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (ubuntu) was (%s).", distro.ID)
+	}
+
+	inspectedPaths := detector.InspectedPaths()
+	for _, expected := range []string{"/etc/lsb-release", "/etc/os-release"} {
+		found := false
+		for _, inspected := range inspectedPaths {
+			if inspected == expected {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("inspected paths %v did not include %s", inspectedPaths, expected)
+		}
+	}
+}
+
+func TestOsReleaseOnlyInRun(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/run/os-release", "NAME=\"Fedora Linux\"\nID=fedora\nVERSION_ID=39\n")
+
+	distro := (&Detector{Root: root}).DiscoverDistro()
+	if distro.ID != "fedora" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (fedora) was (%s).", distro.ID)
+	}
+	if distro.Version != "39" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (39) was (%s).", distro.Version)
+	}
+}
+
+func TestOsReleaseInEtcTakesPrecedence(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Fedora Linux\"\nID=fedora\nVERSION_ID=39\n")
+	writeTestFile(t, root, "/usr/lib/os-release", "NAME=\"Fedora Linux\"\nID=fedora\nVERSION_ID=38\n")
+	writeTestFile(t, root, "/run/os-release", "NAME=\"Fedora Linux\"\nID=fedora\nVERSION_ID=37\n")
+
+	distro := (&Detector{Root: root}).DiscoverDistro()
+	if distro.Version != "39" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (39) was (%s).", distro.Version)
+	}
+}
+
+func TestGzipCompressedOsRelease(t *testing.T) {
+	originalFileSystemRoot := FileSystemRoot
+	t.Cleanup(func() {
+		FileSystemRoot = originalFileSystemRoot
+	})
+
+	root := t.TempDir()
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err := gzipWriter.Write([]byte("NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.13.2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, root, "/etc/os-release.gz", compressed.String())
+
+	FileSystemRoot = root
+	distro := NewDetector().DiscoverDistro()
+	if distro.ID != "alpine" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (alpine) was (%s).", distro.ID)
+	}
+	if distro.OsRelease["VERSION_ID"] != "3.13.2" {
+		t.Errorf("os-release was not decompressed correctly. Expected (3.13.2) was (%s).",
+			distro.OsRelease["VERSION_ID"])
+	}
+}
+
+func TestOsReleaseTakesPrecedenceOverGzipCompressedOsRelease(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Fedora Linux\"\nID=fedora\nVERSION_ID=39\n")
+	writeTestFile(t, root, "/etc/os-release.gz", "not gzip compressed")
+
+	detector := &Detector{Root: root}
+	result, err := detector.DiscoverDistroE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Distro.ID != "fedora" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (fedora) was (%s).", result.Distro.ID)
+	}
+}
+
+func TestCandidatePathsOsRelease(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/mnt/release/os-release", "NAME=\"Fedora Linux\"\nID=fedora\nVERSION_ID=39\n")
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Ubuntu\"\nID=ubuntu\nVERSION_ID=\"20.04\"\n")
+
+	detector := &Detector{
+		Root: root,
+		CandidatePaths: map[string][]string{
+			"os-release": {"/mnt/release/os-release"},
+		},
+	}
+	distro := detector.DiscoverDistro()
+	if distro.ID != "fedora" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (fedora) was (%s).", distro.ID)
+	}
+	if distro.Version != "39" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (39) was (%s).", distro.Version)
+	}
+}
+
+func TestInspectsHostRoot(t *testing.T) {
+	tests := []struct {
+		root     string
+		expected bool
+	}{
+		{root: "", expected: true},
+		{root: string(os.PathSeparator), expected: true},
+		{root: filepath.FromSlash("/mnt/image/"), expected: false},
+	}
+
+	for _, test := range tests {
+		detector := &Detector{Root: test.root}
+		if detector.inspectsHostRoot() != test.expected {
+			t.Errorf("host root was not recognized correctly for root (%s). Expected (%t) was (%t).",
+				test.root, test.expected, !test.expected)
+		}
+	}
+}
+
+func TestRootedPath(t *testing.T) {
+	tests := []struct {
+		root     string
+		expected string
+	}{
+		{root: string(os.PathSeparator), expected: "/etc/os-release"},
+		{root: "/mnt/image/", expected: filepath.FromSlash("/mnt/image/etc/os-release")},
+		// The drive letter and backslashes of a Windows root are kept as is
+		{root: `C:\images\root`, expected: `C:\images\root` + string(os.PathSeparator) + filepath.FromSlash("etc/os-release")},
+	}
+
+	for _, test := range tests {
+		detector := &Detector{Root: test.root}
+		rootedPath := detector.rootedPath("/etc/os-release")
+		if rootedPath != test.expected {
+			t.Errorf("path was not rooted correctly for root (%s). Expected (%s) was (%s).", test.root,
+				test.expected, rootedPath)
+		}
+	}
+}
+
+func TestWithRootIsIndependentOfFileSystemRoot(t *testing.T) {
+	originalFileSystemRoot := FileSystemRoot
+	t.Cleanup(func() {
+		FileSystemRoot = originalFileSystemRoot
+	})
+
+	alpineRoot := t.TempDir()
+	writeTestFile(t, alpineRoot, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\n")
+	fedoraRoot := t.TempDir()
+	writeTestFile(t, fedoraRoot, "/etc/os-release", "NAME=Fedora\nID=fedora\nVERSION_ID=33\n")
+
+	FileSystemRoot = fedoraRoot
+	detector := WithRoot(alpineRoot)
+	if distro := detector.DiscoverDistro(); distro.ID != "alpine" {
+		t.Errorf("setting FileSystemRoot affected a WithRoot detector. Expected (alpine) was (%s).", distro.ID)
+	}
+	if FileSystemRoot != fedoraRoot {
+		t.Errorf("detecting with a WithRoot detector changed FileSystemRoot to (%s)", FileSystemRoot)
+	}
+	if distro := NewDetector().DiscoverDistro(); distro.ID != "fedora" {
+		t.Errorf("a WithRoot detector affected the default detector. Expected (fedora) was (%s).", distro.ID)
+	}
+}
+
+func TestTimingHooks(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Example Linux\"\nID=example\nVERSION_ID=1\n")
+
+	detectorRuns := map[string]int{}
+	var filesRead []string
+	detector := &Detector{
+		Root: root,
+		OnDetectorRun: func(name string, duration time.Duration) {
+			detectorRuns[name]++
+			if duration < 0 {
+				t.Errorf("negative duration reported for detector (%s): %v", name, duration)
+			}
+		},
+		OnFileRead: func(filePath string, _ time.Duration) {
+			filesRead = append(filesRead, filePath)
+		},
+	}
+	detector.DiscoverDistro()
+
+	detectorNames := distroTestNames(distroTestTable)
+	if len(detectorRuns) != len(detectorNames) {
+		t.Errorf("hook was not called for every detector. Expected (%d) was (%d).", len(detectorNames),
+			len(detectorRuns))
+	}
+	for _, name := range detectorNames {
+		if detectorRuns[name] != 1 {
+			t.Errorf("hook was called (%d) times for detector (%s)", detectorRuns[name], name)
+		}
+	}
+
+	expectedFilesRead := []string{filepath.Join(root, "etc", "os-release")}
+	if !reflect.DeepEqual(filesRead, expectedFilesRead) {
+		t.Errorf("unexpected files reported as read. Expected (%v) was (%v).", expectedFilesRead, filesRead)
+	}
+}
+
+func TestDiscoverDistroCached(t *testing.T) {
+	originalFileSystemRoot := FileSystemRoot
+	originalReadBinaryFileFunc := readBinaryFileFunc
+	reads := 0
+	readBinaryFileFunc = func(d *Detector, filePaths []string) (io.ReadCloser, string, error) {
+		reads++
+		return originalReadBinaryFileFunc(d, filePaths)
+	}
+	t.Cleanup(func() {
+		FileSystemRoot = originalFileSystemRoot
+		readBinaryFileFunc = originalReadBinaryFileFunc
+		InvalidateCache()
+	})
+
+	alpineRoot := t.TempDir()
+	writeTestFile(t, alpineRoot, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\n")
+	fedoraRoot := t.TempDir()
+	writeTestFile(t, fedoraRoot, "/etc/os-release", "NAME=Fedora\nID=fedora\nVERSION_ID=33\n")
+
+	InvalidateCache()
+	FileSystemRoot = alpineRoot
+	if distro := DiscoverDistroCached(); distro.ID != "alpine" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (alpine) was (%s).", distro.ID)
+	}
+	readsAfterFirstCall := reads
+	if distro := DiscoverDistroCached(); distro.ID != "alpine" {
+		t.Errorf("Linux distro id was not cached correctly. Expected (alpine) was (%s).", distro.ID)
+	}
+	if reads != readsAfterFirstCall {
+		t.Errorf("files were read again by the second call: %d reads", reads-readsAfterFirstCall)
+	}
+
+	FileSystemRoot = fedoraRoot
+	if distro := DiscoverDistroCached(); distro.ID != "fedora" {
+		t.Errorf("changing the root did not bypass the cache. Expected (fedora) was (%s).", distro.ID)
+	}
+
+	readsBeforeInvalidation := reads
+	InvalidateCache()
+	DiscoverDistroCached()
+	if reads == readsBeforeInvalidation {
+		t.Error("files were not read again after invalidating the cache")
+	}
+}
+
+func TestInspectedPathsNotRecordedByDefault(t *testing.T) {
+	detector := &Detector{Root: t.TempDir()}
+	detector.DiscoverDistro()
+
+	if len(detector.InspectedPaths()) > 0 {
+		t.Errorf("inspected paths should be empty when recording is disabled: %v", detector.InspectedPaths())
+	}
+}
+
+func TestReleaseFileHashes(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\nPRETTY_NAME=\"Alpine Linux v3.12\"\nHOME_URL=\"https://alpinelinux.org/\"\nBUG_REPORT_URL=\"https://bugs.alpinelinux.org/\"\n")
+
+	detector := &Detector{
+		Root:             root,
+		HashReleaseFiles: true,
+	}
+	distro := detector.DiscoverDistro()
+
+	expected := "930af1fae859f9d287fa48295185091d971f0a0bb5291ca5977bd31f9b974693"
+	if distro.ReleaseFileHashes["/etc/os-release"] != expected {
+		t.Errorf("os-release hash was not calculated correctly. Expected (%s) was (%s).", expected,
+			distro.ReleaseFileHashes["/etc/os-release"])
+	}
+	if _, ok := distro.ReleaseFileHashes["/etc/lsb-release"]; ok {
+		t.Error("a hash was recorded for a file that doesn't exist")
+	}
+}
+
+func TestReleaseFileHashesReusedDetector(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\n")
+
+	detector := &Detector{
+		Root:             root,
+		HashReleaseFiles: true,
+	}
+	first := detector.DiscoverDistro()
+	firstHashes := len(first.ReleaseFileHashes)
+
+	writeTestFile(t, root, "/etc/lsb-release", "DISTRIB_ID=Alpine\nDISTRIB_RELEASE=3.12.1\n")
+	second := detector.DiscoverDistro()
+	if len(first.ReleaseFileHashes) != firstHashes {
+		t.Errorf("the hashes of the first detection were modified by the second: %v", first.ReleaseFileHashes)
+	}
+	if _, ok := second.ReleaseFileHashes["/etc/lsb-release"]; !ok {
+		t.Errorf("lsb-release hash was not recorded by the second detection: %v", second.ReleaseFileHashes)
+	}
+}
+
+func TestInspectedPathsReusedDetector(t *testing.T) {
+	root := t.TempDir()
+	detector := &Detector{
+		Root:                 root,
+		RecordInspectedPaths: true,
+	}
+	// Nothing is detected in an empty root, so the fallbacks inspect additional paths
+	detector.DiscoverDistro()
+
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\n")
+	detector.DiscoverDistro()
+
+	freshDetector := &Detector{
+		Root:                 root,
+		RecordInspectedPaths: true,
+	}
+	freshDetector.DiscoverDistro()
+	if !reflect.DeepEqual(detector.InspectedPaths(), freshDetector.InspectedPaths()) {
+		t.Errorf("inspected paths accumulated across detections. Expected %v was %v.",
+			freshDetector.InspectedPaths(), detector.InspectedPaths())
+	}
+}
+
+func TestReleaseFileHashesNotRecordedByDefault(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\n")
+
+	distro := (&Detector{Root: root}).DiscoverDistro()
+	if distro.ReleaseFileHashes != nil {
+		t.Errorf("hashes should not be recorded when hashing is disabled: %v", distro.ReleaseFileHashes)
+	}
+}
+
+func TestPrettyNameUbuntu2004(t *testing.T) {
+	lsbProperties := map[string]string{
+		"DISTRIB_ID":          "Ubuntu",
+		"DISTRIB_RELEASE":     "20.04",
+		"DISTRIB_CODENAME":    "focal",
+		"DISTRIB_DESCRIPTION": "Ubuntu 20.04.1 LTS",
+	}
+	osReleaseProperties := map[string]string{
+		"NAME":             "Ubuntu",
+		"VERSION":          "20.04.1 LTS (Focal Fossa)",
+		"ID":               "ubuntu",
+		"ID_LIKE":          "debian",
+		"PRETTY_NAME":      "Ubuntu 20.04.1 LTS",
+		"VERSION_ID":       "20.04",
+		"VERSION_CODENAME": "focal",
+		"UBUNTU_CODENAME":  "focal",
+	}
+
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if distro.PrettyName != "Ubuntu 20.04.1 LTS" {
+		t.Errorf("pretty name was not detected correctly. Expected (%s) was (%s).", "Ubuntu 20.04.1 LTS",
+			distro.PrettyName)
+	}
+}
+
+func TestPrettyNameFallsBackToLsbDescription(t *testing.T) {
+	lsbProperties := map[string]string{
+		"DISTRIB_ID":          "Ubuntu",
+		"DISTRIB_RELEASE":     "20.04",
+		"DISTRIB_DESCRIPTION": "Ubuntu 20.04.1 LTS",
+	}
+	osReleaseProperties := map[string]string{}
+
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if distro.PrettyName != "Ubuntu 20.04.1 LTS" {
+		t.Errorf("pretty name was not detected correctly. Expected (%s) was (%s).", "Ubuntu 20.04.1 LTS",
+			distro.PrettyName)
+	}
+}
+
+func TestPrettyNameFallsBackToNameAndVersion(t *testing.T) {
+	lsbProperties := map[string]string{}
+	osReleaseProperties := map[string]string{
+		"NAME":       "Alpine Linux",
+		"ID":         "alpine",
+		"VERSION_ID": "3.12.0",
+	}
+
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if distro.PrettyName != "Alpine Linux 3.12.0" {
+		t.Errorf("pretty name was not detected correctly. Expected (%s) was (%s).", "Alpine Linux 3.12.0",
+			distro.PrettyName)
+	}
+}
+
+func TestLibcMusl(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.18.4\n")
+	writeTestFile(t, root, "/lib/ld-musl-x86_64.so.1", "")
+
+	distro := (&Detector{Root: root}).DiscoverDistro()
+	if distro.Libc != "musl" {
+		t.Errorf("libc was not detected correctly. Expected (musl) was (%s).", distro.Libc)
+	}
+}
+
+func TestLibcGlibc(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Ubuntu\"\nID=ubuntu\nVERSION_ID=\"20.04\"\n")
+	writeTestFile(t, root, "/lib/x86_64-linux-gnu/libc.so.6", "")
+
+	distro := (&Detector{Root: root}).DiscoverDistro()
+	if distro.Libc != "glibc" {
+		t.Errorf("libc was not detected correctly. Expected (glibc) was (%s).", distro.Libc)
+	}
+}
+
+func TestLibcGlibcWithMuslInstalled(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Debian GNU/Linux\"\nID=debian\nVERSION_ID=\"12\"\n")
+	writeTestFile(t, root, "/lib/x86_64-linux-gnu/libc.so.6", "")
+	writeTestFile(t, root, "/lib/ld-musl-x86_64.so.1", "")
+
+	distro := (&Detector{Root: root}).DiscoverDistro()
+	if distro.Libc != "glibc" {
+		t.Errorf("libc was not detected correctly. Expected (glibc) was (%s).", distro.Libc)
+	}
+}
+
+func TestLibcInferredFromDistro(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.18.4\n")
+
+	distro := (&Detector{Root: root}).DiscoverDistro()
+	if distro.Libc != "musl" {
+		t.Errorf("libc was not detected correctly. Expected (musl) was (%s).", distro.Libc)
+	}
+}
+
+func TestHardwareModelFromDeviceTree(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/proc/device-tree/model", "/sys/firmware/devicetree/base/model"}) {
+			return true, "Raspberry Pi 4 Model B Rev 1.4\x00"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+
+	model := NewDetector().detectHardwareModel()
+	if model != "Raspberry Pi 4 Model B Rev 1.4" {
+		t.Errorf("hardware model was not detected correctly. Expected (Raspberry Pi 4 Model B Rev 1.4) was (%q).",
+			model)
+	}
+}
+
+func TestHardwareModelWithoutDeviceTree(t *testing.T) {
+	model := NewDetector().detectHardwareModel()
+	if model != "" {
+		t.Errorf("hardware model was detected without a device tree: %q", model)
+	}
+}
+
+func TestVirtualizationVMware(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/sys/class/dmi/id/sys_vendor":   "VMware, Inc.\n",
+		"/sys/class/dmi/id/product_name": "VMware Virtual Platform\n",
+	})
+
+	virtualization := NewDetector().detectVirtualization()
+	if virtualization != "vmware" {
+		t.Errorf("virtualization was not detected correctly. Expected (vmware) was (%s).", virtualization)
+	}
+}
+
+func TestVirtualizationQEMU(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/sys/class/dmi/id/sys_vendor":   "QEMU\n",
+		"/sys/class/dmi/id/product_name": "Standard PC (Q35 + ICH9, 2009)\n",
+	})
+
+	virtualization := NewDetector().detectVirtualization()
+	if virtualization != "qemu" {
+		t.Errorf("virtualization was not detected correctly. Expected (qemu) was (%s).", virtualization)
+	}
+}
+
+func TestVirtualizationBareMetal(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/sys/class/dmi/id/sys_vendor":   "Microsoft Corporation\n",
+		"/sys/class/dmi/id/product_name": "Surface Laptop 4\n",
+	})
+
+	virtualization := NewDetector().detectVirtualization()
+	if virtualization != "" {
+		t.Errorf("virtualization was detected on bare metal: %s", virtualization)
+	}
+}
+
+func TestUbuntuProWithStatusFile(t *testing.T) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		if reflect.DeepEqual(filePaths, []string{"/var/lib/ubuntu-advantage/status.json"}) {
+			return true, "{\"attached\": true}"
+		} else {
+			return false, ""
+		}
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+
+	if !NewDetector().detectUbuntuPro(LinuxDistro{ID: "ubuntu"}) {
+		t.Error("Ubuntu Pro was not detected with the status file present")
+	}
+	if NewDetector().detectUbuntuPro(LinuxDistro{ID: "debian"}) {
+		t.Error("Ubuntu Pro was detected on a distro other than Ubuntu")
+	}
+}
+
+func TestUbuntuProWithUnattachedStatusFile(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/var/lib/ubuntu-advantage/status.json": "{\"attached\": false, \"services\": []}",
+	})
+
+	if NewDetector().detectUbuntuPro(LinuxDistro{ID: "ubuntu"}) {
+		t.Error("Ubuntu Pro was detected with a status file that isn't attached")
+	}
+}
+
+func TestUbuntuProWithInvalidStatusFile(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/var/lib/ubuntu-advantage/status.json": "not json",
+	})
+
+	if NewDetector().detectUbuntuPro(LinuxDistro{ID: "ubuntu"}) {
+		t.Error("Ubuntu Pro was detected with an invalid status file")
+	}
+}
+
+func TestUbuntuProWithoutStatusFile(t *testing.T) {
+	if NewDetector().detectUbuntuPro(LinuxDistro{ID: "ubuntu"}) {
+		t.Error("Ubuntu Pro was detected without the status file")
+	}
+}
+
+func TestMergedPropertiesMXLinux(t *testing.T) {
+	distro := LinuxDistro{
+		LsbRelease: map[string]string{
+			"PRETTY_NAME":         "MX 19.2 patito feo",
+			"DISTRIB_ID":          "MX",
+			"DISTRIB_RELEASE":     "19.2",
+			"DISTRIB_CODENAME":    "patito feo",
+			"DISTRIB_DESCRIPTION": "MX 19.2 patito feo",
+		},
+		OsRelease: map[string]string{
+			"VERSION_ID":       "10",
+			"VERSION":          "10 (buster)",
+			"VERSION_CODENAME": "buster",
+			"SUPPORT_URL":      "https://www.debian.org/support",
+			"BUG_REPORT_URL":   "https://bugs.debian.org/",
+			"PRETTY_NAME":      "Debian GNU/Linux 10 (buster)",
+			"NAME":             "Debian GNU/Linux",
+			"ID":               "debian",
+			"HOME_URL":         "https://www.debian.org/",
+		},
+	}
+
+	expected := ReleaseDetails{
+		"VERSION_ID":          "10",
+		"VERSION":             "10 (buster)",
+		"VERSION_CODENAME":    "buster",
+		"SUPPORT_URL":         "https://www.debian.org/support",
+		"BUG_REPORT_URL":      "https://bugs.debian.org/",
+		"PRETTY_NAME":         "Debian GNU/Linux 10 (buster)",
+		"NAME":                "Debian GNU/Linux",
+		"ID":                  "debian",
+		"HOME_URL":            "https://www.debian.org/",
+		"DISTRIB_DESCRIPTION": "MX 19.2 patito feo",
+	}
+
+	merged := distro.MergedProperties()
+	if !reflect.DeepEqual(expected, merged) {
+		t.Errorf("merged properties were not correct. Expected (%v) was (%v).", expected, merged)
+	}
+}
+
+func TestMergedPropertiesLsbFillsGaps(t *testing.T) {
+	distro := LinuxDistro{
+		LsbRelease: map[string]string{
+			"DISTRIB_ID":       "Ubuntu",
+			"DISTRIB_RELEASE":  "20.04",
+			"DISTRIB_CODENAME": "focal",
+		},
+		OsRelease: map[string]string{
+			"NAME": "Ubuntu",
+		},
+	}
+
+	expected := ReleaseDetails{
+		"ID":               "Ubuntu",
+		"VERSION_ID":       "20.04",
+		"VERSION_CODENAME": "focal",
+		"NAME":             "Ubuntu",
+	}
+
+	merged := distro.MergedProperties()
+	if !reflect.DeepEqual(expected, merged) {
+		t.Errorf("merged properties were not correct. Expected (%v) was (%v).", expected, merged)
+	}
+}
+
+func TestNormalizedVersionRancherOS(t *testing.T) {
+	lsbProperties := map[string]string{
+		"DISTRIB_ID":          "RancherOS",
+		"DISTRIB_RELEASE":     "v1.5.6",
+		"DISTRIB_DESCRIPTION": "RancherOS v1.5.6",
+	}
+	osReleaseProperties := map[string]string{
+		"ID":          "rancheros",
+		"VERSION_ID":  "v1.5.6",
+		"PRETTY_NAME": "RancherOS v1.5.6",
+	}
+
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	if distro.Version != "v1.5.6" {
+		t.Errorf("raw version should be unchanged. Expected (v1.5.6) was (%s).", distro.Version)
+	}
+	if distro.NormalizedVersion() != "1.5.6" {
+		t.Errorf("normalized version is incorrect. Expected (1.5.6) was (%s).", distro.NormalizedVersion())
+	}
+}
+
+func TestNormalizedVersionUnchanged(t *testing.T) {
+	for _, version := range []string{"20.04", "rolling", "v", "vsomething", ""} {
+		distro := LinuxDistro{Version: version}
+		if distro.NormalizedVersion() != version {
+			t.Errorf("version (%s) should not have been changed by normalization: %s", version,
+				distro.NormalizedVersion())
+		}
+	}
+}
+
+func TestResult(t *testing.T) {
+	distro := LinuxDistro{
+		Name:    "Ubuntu",
+		ID:      "ubuntu",
+		Version: "20.04",
+		OsRelease: map[string]string{
+			"NAME":       "Ubuntu",
+			"ID":         "ubuntu",
+			"VERSION_ID": "20.04",
+		},
+	}
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{key: "id", expected: "ubuntu"},
+		{key: "version", expected: "20.04"},
+		{key: "os_release", expected: "ID=ubuntu\nNAME=Ubuntu\nVERSION_ID=20.04"},
+	}
+
+	for _, test := range tests {
+		value, ok := distro.Result(test.key)
+		if !ok {
+			t.Errorf("key (%s) was not known", test.key)
+		}
+		if value != test.expected {
+			t.Errorf("unexpected value for key (%s). Expected (%q) was (%q).", test.key, test.expected, value)
+		}
+	}
+}
+
+func TestResultUnknownKey(t *testing.T) {
+	distro := LinuxDistro{ID: "ubuntu"}
+
+	if _, ok := distro.Result("bogus"); ok {
+		t.Error("unknown key was reported as known")
+	}
+}
+
+func TestEOLDate(t *testing.T) {
+	tests := []struct {
+		distro   LinuxDistro
+		expected string
+	}{
+		{distro: LinuxDistro{ID: "ubuntu", Version: "18.04"}, expected: "2023-05-31"},
+		{distro: LinuxDistro{ID: "centos", Version: "8.5.2111"}, expected: "2021-12-31"},
+		{distro: LinuxDistro{ID: "ubuntu", Version: "18.10"}, expected: ""},
+		{distro: LinuxDistro{ID: "arch", Version: "rolling"}, expected: ""},
+	}
+
+	for _, test := range tests {
+		eolDate, ok := test.distro.EOLDate()
+		actual := ""
+		if ok {
+			actual = eolDate.Format("2006-01-02")
+		}
+		if actual != test.expected {
+			t.Errorf("end of life date for %s %s was not correct. Expected (%s) was (%s).", test.distro.ID,
+				test.distro.Version, test.expected, actual)
+		}
+	}
+	if !(&LinuxDistro{ID: "centos", Version: "8"}).IsEOL() {
+		t.Error("CentOS 8 was not end of life")
+	}
+}
+
+func TestExtractParenthetical(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{version: "10 (buster)", expected: "buster"},
+		{version: "12 (bookworm)", expected: "bookworm"},
+		{version: "7.6 (Maipo)", expected: "Maipo"},
+		{version: "9.3 (Plow)", expected: "Plow"},
+		{version: "7 (Core)", expected: "Core"},
+		{version: "20.04.1 LTS (Focal Fossa)", expected: "Focal Fossa"},
+		{version: "22.04.3 LTS (Jammy Jellyfish)", expected: "Jammy Jellyfish"},
+		{version: "14.04, Trusty Tahr", expected: ""},
+		{version: "7.9", expected: ""},
+		{version: "", expected: ""},
+		{version: "1 (a) (b)", expected: "b"},
+		{version: "1 (unterminated", expected: ""},
+	}
+
+	for _, test := range tests {
+		actual := extractParenthetical(test.version)
+		if actual != test.expected {
+			t.Errorf("unexpected parenthetical for (%s). Expected (%s) was (%s).", test.version, test.expected,
+				actual)
+		}
+	}
+}
+
+func TestCodename(t *testing.T) {
+	rhel := LinuxDistro{OsRelease: map[string]string{"VERSION": "7.6 (Maipo)"}}
+	if rhel.Codename() != "Maipo" {
+		t.Errorf("codename was not detected correctly. Expected (Maipo) was (%s).", rhel.Codename())
+	}
+
+	ubuntu := LinuxDistro{OsRelease: map[string]string{
+		"VERSION":          "20.04.1 LTS (Focal Fossa)",
+		"VERSION_CODENAME": "focal",
+	}}
+	if ubuntu.Codename() != "focal" {
+		t.Errorf("codename was not detected correctly. Expected (focal) was (%s).", ubuntu.Codename())
+	}
+}
+
+func TestWriteJSONMatchesMarshalIndent(t *testing.T) {
+	lsbProperties := map[string]string{
+		"DISTRIB_ID":          "Ubuntu",
+		"DISTRIB_RELEASE":     "20.04",
+		"DISTRIB_CODENAME":    "focal",
+		"DISTRIB_DESCRIPTION": "Ubuntu 20.04.1 LTS",
+	}
+	osReleaseProperties := map[string]string{
+		"NAME":             "Ubuntu",
+		"VERSION":          "20.04.1 LTS (Focal Fossa)",
+		"ID":               "ubuntu",
+		"ID_LIKE":          "debian",
+		"PRETTY_NAME":      "Ubuntu 20.04.1 LTS",
+		"VERSION_ID":       "20.04",
+		"VERSION_CODENAME": "focal",
+		"UBUNTU_CODENAME":  "focal",
+	}
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+
+	expected, err := json.MarshalIndent(distro, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual bytes.Buffer
+	if err := distro.WriteJSON(&actual, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.String() != string(expected)+env.LineBreak {
+		t.Errorf("unexpected JSON output. Expected:\n%s\nActual:\n%s", expected, actual.String())
+	}
+}
+
+func TestWriteJSONOneLine(t *testing.T) {
+	distro := LinuxDistro{Name: "Ubuntu", ID: "ubuntu", Version: "20.04"}
+
+	var actual bytes.Buffer
+	if err := distro.WriteJSON(&actual, false); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"id":"ubuntu","name":"Ubuntu","version":"20.04","detected_at":"0001-01-01T00:00:00Z","lsb_release":null,"os_release":null}` + env.LineBreak
+	if actual.String() != expected {
+		t.Errorf("unexpected JSON output. Expected:\n%s\nActual:\n%s", expected, actual.String())
+	}
+}
+
+// countingWriter is a buffer that counts the number of times that it was written to.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.writes++
+	return c.Buffer.Write(p)
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// blockingReader is a reader that blocks until it is unblocked in order to simulate a slow mount.
+type blockingReader struct {
+	unblock chan struct{}
+}
+
+func (b *blockingReader) Read(_ []byte) (int, error) {
+	<-b.unblock
+	return 0, io.EOF
+}
+
+func (b *blockingReader) Close() error {
+	return nil
+}
+
+func writeTestFile(t *testing.T, root string, filePath string, contents string) {
+	fullPath := filepath.Join(root, filepath.FromSlash(filePath))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(fullPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func distroIsDetectedBasedOnProperties(t *testing.T, id string, name string, version string, lsbProperties map[string]string,
-	osReleaseProperties map[string]string) {
-	distro := discoverDistroFromProperties(lsbProperties, osReleaseProperties)
+	osReleaseProperties map[string]string) LinuxDistro {
+	distro := NewDetector().discoverDistroFromProperties(lsbProperties, osReleaseProperties)
 	if distro.ID != id {
 		t.Errorf("Linux distro id was not detected correctly. Expected (%s) was (%s).", id, distro.ID)
 	}
@@ -1536,4 +4965,6 @@ func distroIsDetectedBasedOnProperties(t *testing.T, id string, name string, ver
 	if !reflect.DeepEqual(osReleaseProperties, distro.OsRelease) {
 		t.Error("OS release properties weren't copied properly into distro struct")
 	}
+
+	return distro
 }
@@ -4,24 +4,34 @@ import (
 	"bufio"
 	"io"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 )
 
-func IsAlpine(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "alpine" {
+func isAlpine(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "alpine" {
+		version := osReleaseProperties["VERSION_ID"]
+		if isAlpineEdge(version) || strings.HasSuffix(osReleaseProperties["PRETTY_NAME"], " edge") {
+			version = "edge"
+		}
+
 		return true, LinuxDistro{
 			Name:       "Alpine Linux",
 			ID:         "alpine",
-			Version:    osReleaseProperties["VERSION_ID"],
+			Version:    version,
 			LsbRelease: lsbProperties,
 			OsRelease:  osReleaseProperties,
 		}
 	}
 
-	exists, content := readFileFunc("/etc/alpine-release")
+	exists, content := d.readNonEmptyFile(d.candidatePaths("IsAlpine", "/etc/alpine-release")...)
 	if exists {
 		version := strings.TrimSpace(content)
+		if isAlpineEdge(version) {
+			version = "edge"
+		}
+
 		return true, LinuxDistro{
 			Name:       "Alpine Linux",
 			ID:         "alpine",
@@ -34,8 +44,14 @@ func IsAlpine(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 	return false, LinuxDistro{}
 }
 
-func IsAlt(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "altlinux" {
+// isAlpineEdge determines if an Alpine version string belongs to the rolling edge branch, which
+// reports either "edge" or the upcoming release with an alpha suffix (eg 3.19.0_alpha20231219).
+func isAlpineEdge(version string) bool {
+	return version == "edge" || strings.Contains(version, "_alpha")
+}
+
+func isAlt(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "altlinux" {
 		return true, LinuxDistro{
 			Name:       "ALT Starterkit",
 			ID:         "altlinux",
@@ -47,39 +63,54 @@ func IsAlt(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bo
 	return false, LinuxDistro{}
 }
 
-func IsAmazonLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] != "amzn" {
+func isAmazonLinux(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) != "amzn" {
 		return false, LinuxDistro{}
 	}
 
+	// Amazon Linux 1 (Amazon Linux AMI) is versioned by the year and month of its release
+	name := "Amazon Linux"
+	version := osReleaseProperties["VERSION_ID"]
+	if amazonLinuxAMIVersion.MatchString(version) {
+		name = "Amazon Linux AMI " + version
+	}
+
 	return true, LinuxDistro{
-		Name:       "Amazon Linux",
+		Name:       name,
 		ID:         "amzn",
-		Version:    osReleaseProperties["VERSION_ID"],
+		Version:    version,
 		LsbRelease: lsbProperties,
 		OsRelease:  osReleaseProperties,
 	}
 }
 
-func IsAndroid(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	exists, contents := readFileFunc("/system/build.prop")
+// amazonLinuxAMIVersion matches the year based versions (eg 2018.03) of Amazon Linux 1.
+var amazonLinuxAMIVersion = regexp.MustCompile("^[0-9]{4}\\.[0-9]{2}$")
+
+func isAndroid(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsAndroid", "/system/build.prop")...)
 	if exists {
 		version := "unknown"
+		var sdkVersion string
 
 		reader := strings.NewReader(contents)
 		releaseInfo, err := parseOSRelease(reader)
 		if err == nil {
-			if releaseInfo["ro.com.google.gmsversion"] != "" {
-				version = releaseInfo["ro.com.google.gmsversion"]
-			} else if releaseInfo["ro.build.version.release"] != "" {
+			// The OS version is preferred because the GMS version is a Google Mobile Services
+			// package version (eg 9.0_r1) and is absent on AOSP builds.
+			if releaseInfo["ro.build.version.release"] != "" {
 				version = releaseInfo["ro.build.version.release"]
+			} else if releaseInfo["ro.com.google.gmsversion"] != "" {
+				version = releaseInfo["ro.com.google.gmsversion"]
 			}
+			sdkVersion = releaseInfo["ro.build.version.sdk"]
 		}
 
 		return true, LinuxDistro{
 			Name:       "Android",
 			ID:         "android",
 			Version:    version,
+			SDKVersion: sdkVersion,
 			LsbRelease: lsbProperties,
 			OsRelease:  osReleaseProperties,
 		}
@@ -88,8 +119,8 @@ func IsAndroid(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 	return false, LinuxDistro{}
 }
 
-func IsArchLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] != "arch" {
+func isArchLinux(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) != "arch" {
 		return false, LinuxDistro{}
 	}
 
@@ -102,34 +133,42 @@ func IsArchLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetail
 	}
 }
 
-func IsBusyBox(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+func isBusyBox(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
 	// BusyBox isn't really a distro, but rather a collection of applications. We want to rule out the
 	// chance that a distro was built using the BusyBox binaries before we indicate that the system is
 	// BusyBox.
-	exists, _ := readFileFunc("/etc/os-release", "/etc/lsb-release")
+	exists, _ := d.readFile("/etc/os-release", "/etc/lsb-release")
 	if exists {
 		return false, LinuxDistro{}
 	}
 
+	if !d.AllowExec {
+		for _, filePath := range d.candidatePaths("IsBusyBox", "/bin/true") {
+			if _, err := d.stat(filePath); err == nil {
+				d.warnf("BusyBox detection was skipped because reading executables isn't allowed")
+				break
+			}
+		}
+		return false, LinuxDistro{}
+	}
+
 	searchBytes := "BusyBox v"
 	searchBytesSize := len(searchBytes)
 
-	reader, filePath, openErr := readBinaryFileFunc([]string{"/bin/true"})
+	file, filePath, openErr := d.readBinaryFile(d.candidatePaths("IsBusyBox", "/bin/true")...)
 	if openErr != nil {
 		return false, LinuxDistro{}
 	}
 
-	defer func() { _ = reader.Close() }()
+	defer func() { _ = file.Close() }()
 
-	buf := make([]byte, searchBytesSize+5)
+	reader := bufio.NewReader(file)
 	matchedPos := 0
 	foundBusyBox := false
-	foundVersion := false
 	var version string
-	position := -1
 
 	for {
-		n, err := reader.Read(buf)
+		b, err := reader.ReadByte()
 		if err == io.EOF {
 			break
 		} else if err != nil {
@@ -137,33 +176,29 @@ func IsBusyBox(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 			return false, LinuxDistro{}
 		}
 
-		for i := 0; matchedPos < searchBytesSize && i < n-1; i++ {
-			position++
-
-			if foundBusyBox {
-				char := rune(buf[i])
-				if unicode.IsDigit(char) || char == '.' {
-					version += string(char)
-				} else if len(version) < 6 {
-					foundBusyBox = false
-					matchedPos = 0
-				} else if len(version) >= 6 {
-					foundVersion = true
-					break
-				}
-			} else if buf[i] == searchBytes[matchedPos] {
-				if matchedPos+1 == searchBytesSize {
-					foundBusyBox = true
-				} else {
-					matchedPos++
-				}
-			} else {
+		if matchedPos == searchBytesSize {
+			if unicode.IsDigit(rune(b)) || b == '.' {
+				version += string(b)
+				continue
+			}
+
+			// The version must look like a dotted version number (eg 1.32.0), otherwise we have matched
+			// a string such as a format string that merely mentions BusyBox
+			if strings.Contains(version, ".") && unicode.IsDigit(rune(version[0])) {
+				foundBusyBox = true
 				break
 			}
+
+			matchedPos = 0
+			version = ""
 		}
 
-		if foundBusyBox && foundVersion {
-			break
+		if b == searchBytes[matchedPos] {
+			matchedPos++
+		} else if b == searchBytes[0] {
+			matchedPos = 1
+		} else {
+			matchedPos = 0
 		}
 	}
 
@@ -180,18 +215,15 @@ func IsBusyBox(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 	}
 }
 
-func IsCentOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	// Oracle Linux tries to impersonate Red Hat, so we look to see if the oracle release file is present,
-	// if so, we know that this isn't Redhat.
-	imOracle, distro := IsOracleLinux(lsbProperties, osReleaseProperties)
-	if imOracle {
-		return imOracle, distro
-	}
-
-	exists, contents := readFileFunc("/etc/centos-release", "/etc/redhat-release")
+func isCentOS(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsCentOS", "/etc/centos-release", "/etc/redhat-release")...)
 	if exists {
 		matched, version := parseRedhatReleaseContents(contents, "CentOS")
 		if matched {
+			if osReleaseID(osReleaseProperties) == "rhel" {
+				d.warnf("os-release reports rhel, but the release file reports: %s", strings.TrimSpace(contents))
+			}
+
 			return true, LinuxDistro{
 				Name:       "CentOS Linux",
 				ID:         "centos",
@@ -205,8 +237,35 @@ func IsCentOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 	return false, LinuxDistro{}
 }
 
-func IsClearLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "clear-linux-os" {
+func isChromeOS(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	releaseName := lsbProperties["CHROMEOS_RELEASE_NAME"]
+	if releaseName == "" {
+		return false, LinuxDistro{}
+	}
+
+	var name string
+	if releaseName == "Chromium OS" {
+		name = "Chromium OS"
+	} else {
+		name = "Chrome OS"
+	}
+
+	version := lsbProperties["CHROMEOS_RELEASE_VERSION"]
+	if version == "" {
+		version = "unknown"
+	}
+
+	return true, LinuxDistro{
+		Name:       name,
+		ID:         "chromeos",
+		Version:    version,
+		LsbRelease: lsbProperties,
+		OsRelease:  osReleaseProperties,
+	}
+}
+
+func isClearLinux(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "clear-linux-os" {
 		return true, LinuxDistro{
 			Name:       "Clear Linux OS",
 			ID:         "clear-linux-os",
@@ -218,8 +277,53 @@ func IsClearLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetai
 	return false, LinuxDistro{}
 }
 
-func IsCrux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	exists, contents := readFileFunc("/usr/bin/crux")
+func isClearOS(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsClearOS", "/etc/clearos-release")...)
+	if exists {
+		matched, version := parseRedhatReleaseContents(contents, "ClearOS")
+		if matched {
+			return true, LinuxDistro{
+				Name:       "ClearOS",
+				ID:         "clearos",
+				Version:    version,
+				LsbRelease: lsbProperties,
+				OsRelease:  osReleaseProperties,
+			}
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+func isClonezilla(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if lsbProperties["DISTRIB_ID"] == "Clonezilla" {
+		return true, LinuxDistro{
+			Name:       "Clonezilla Live",
+			ID:         "clonezilla",
+			Version:    lsbProperties["DISTRIB_RELEASE"],
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
+	// Clonezilla Live ships the os-release file of its Debian base, so the DRBL configuration that
+	// it is built upon is the most reliable marker.
+	exists, _ := d.readNonEmptyFile(d.candidatePaths("IsClonezilla", "/etc/drbl/drbl.conf")...)
+	if exists {
+		return true, LinuxDistro{
+			Name:       "Clonezilla Live",
+			ID:         "clonezilla",
+			Version:    "unknown",
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+func isCrux(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsCrux", "/usr/bin/crux")...)
 	if exists {
 		version := "unknown"
 
@@ -253,25 +357,32 @@ func IsCrux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (b
 	return false, LinuxDistro{}
 }
 
-func IsDebian(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	// MX Linux does a good job of impersonating Debian, we test for it first to rule it out
-	iamMx, distro := IsMXLinux(lsbProperties, osReleaseProperties)
-	if iamMx {
-		return iamMx, distro
-	}
-
+func isDebian(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
 	var version string
+	var numericVersion string
 
-	debianVersionExists, versionContents := readFileFunc("/etc/debian_version")
+	debianVersionExists, versionContents := d.readNonEmptyFile(d.candidatePaths("IsDebian", "/etc/debian_version")...)
 	if debianVersionExists {
 		version = strings.TrimSpace(versionContents)
+		// Testing releases only contain the codename (eg trixie), so we also report its release number
+		numericVersion = codenameVersion("debian", version)
+	} else if osReleaseID(osReleaseProperties) == "debian" {
+		// Minimal installations may not have /etc/debian_version, so we fall back to os-release
+		version = osReleaseProperties["VERSION_ID"]
+		if version == "" {
+			version = codenameVersion("debian", osReleaseProperties["VERSION_CODENAME"])
+		}
+		if version == "" {
+			return false, LinuxDistro{}
+		}
 	} else {
 		return false, LinuxDistro{}
 	}
 
-	// Check that this isn't a Debian variant like Ubuntu
-	issueExists, issueContents := readFileFunc("/etc/issue")
-	if issueExists {
+	// Check that this isn't a Debian variant like Ubuntu. The issue file is often customized, so
+	// when os-release explicitly identifies the system as Debian, we trust it over the issue file.
+	issueExists, issueContents := d.readFile("/etc/issue")
+	if issueExists && osReleaseID(osReleaseProperties) != "debian" {
 		if !strings.HasPrefix(issueContents, "Debian") {
 			return false, LinuxDistro{}
 		}
@@ -279,21 +390,22 @@ func IsDebian(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 
 	// After we have checked for the files that would indicate that this is a Debian release,
 	// if we don't have a non-blank Debian os release id and, this isn't a Debian distro.
-	if osReleaseProperties["ID"] != "debian" && osReleaseProperties["ID"] != "" {
+	if osReleaseID(osReleaseProperties) != "debian" && osReleaseID(osReleaseProperties) != "" {
 		return false, LinuxDistro{}
 	}
 
 	return true, LinuxDistro{
-		Name:       "Debian GNU/Linux",
-		ID:         "debian",
-		Version:    version,
-		LsbRelease: lsbProperties,
-		OsRelease:  osReleaseProperties,
+		Name:           "Debian GNU/Linux",
+		ID:             "debian",
+		Version:        version,
+		NumericVersion: numericVersion,
+		LsbRelease:     lsbProperties,
+		OsRelease:      osReleaseProperties,
 	}
 }
 
-func IsFedora(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "fedora" {
+func isFedora(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "fedora" {
 		return true, LinuxDistro{
 			Name:       "Fedora",
 			ID:         "fedora",
@@ -303,14 +415,7 @@ func IsFedora(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 		}
 	}
 
-	// Oracle Linux tries to impersonate Red Hat, so we look to see if the oracle release file is present,
-	// if so, we know that this isn't Redhat.
-	imOracle, distro := IsOracleLinux(lsbProperties, osReleaseProperties)
-	if imOracle {
-		return imOracle, distro
-	}
-
-	exists, contents := readFileFunc("/etc/redhat-release")
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsFedora", "/etc/redhat-release")...)
 	if exists {
 		matched, version := parseRedhatReleaseContents(contents, "Fedora")
 		if matched {
@@ -327,8 +432,22 @@ func IsFedora(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 	return false, LinuxDistro{}
 }
 
-func IsKali(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "kali" {
+func isFreespire(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "freespire" {
+		return true, LinuxDistro{
+			Name:       "Freespire",
+			ID:         "freespire",
+			Version:    osReleaseProperties["VERSION_ID"],
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+func isKali(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "kali" {
 		return true, LinuxDistro{
 			Name:       "Kali GNU/Linux",
 			ID:         "kali",
@@ -340,11 +459,11 @@ func IsKali(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (b
 	return false, LinuxDistro{}
 }
 
-func IsGentoo(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "gentoo" {
+func isGentoo(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "gentoo" {
 		var version string
 
-		exists, contents := readFileFunc("/etc/gentoo-release")
+		exists, contents := d.readNonEmptyFile(d.candidatePaths("IsGentoo", "/etc/gentoo-release")...)
 		if exists {
 			match, baseSystemVersion := parseRedhatReleaseContents(contents, "Gentoo")
 			if match {
@@ -367,23 +486,51 @@ func IsGentoo(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 	return false, LinuxDistro{}
 }
 
-func IsOpenSuSE(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "opensuse" {
+func isHyperbola(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) != "hyperbola" {
+		return false, LinuxDistro{}
+	}
+
+	// Hyperbola is a long term support fork of Arch, so unlike Arch it has numbered releases
+	version := osReleaseProperties["VERSION_ID"]
+	if version == "" {
+		version = "unknown"
+	}
+
+	return true, LinuxDistro{
+		Name:       "Hyperbola GNU/Linux-libre",
+		ID:         "hyperbola",
+		Version:    version,
+		LsbRelease: lsbProperties,
+		OsRelease:  osReleaseProperties,
+	}
+}
+
+func isOpenSuSE(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	// Leap and Tumbleweed report their edition in the ID
+	switch id := osReleaseID(osReleaseProperties); id {
+	case "opensuse", "opensuse-leap", "opensuse-tumbleweed":
+		// Some minimal images only set VERSION
+		version := osReleaseProperties["VERSION_ID"]
+		if version == "" {
+			version = osReleaseProperties["VERSION"]
+		}
+
 		return true, LinuxDistro{
 			Name:       "openSUSE",
-			ID:         "opensuse",
-			Version:    osReleaseProperties["VERSION_ID"],
+			ID:         id,
+			Version:    version,
 			LsbRelease: lsbProperties,
 			OsRelease:  osReleaseProperties,
 		}
 	}
 
-	exists, contents := readFileFunc("/etc/SuSE-release")
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsOpenSuSE", "/etc/SuSE-release")...)
 	if exists {
 		if strings.HasPrefix(contents, "openSUSE") {
 			var version string
 			releaseDetails, err := parseOSRelease(strings.NewReader(contents))
-			if err == nil {
+			if err == nil && releaseDetails["VERSION"] != "" {
 				version = releaseDetails["VERSION"]
 			} else {
 				version = "unknown"
@@ -402,21 +549,30 @@ func IsOpenSuSE(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails
 	return false, LinuxDistro{}
 }
 
-func IsOracleLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "ol" && osReleaseProperties["VERSION_ID"] != "" {
-		return true, LinuxDistro{
-			Name:       "Oracle Linux",
-			ID:         "ol",
-			Version:    osReleaseProperties["VERSION_ID"],
-			LsbRelease: lsbProperties,
-			OsRelease:  osReleaseProperties,
+func isOracleLinux(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "ol" {
+		// Minimal images may blank VERSION_ID but still carry the support product version
+		version := osReleaseProperties["VERSION_ID"]
+		if version == "" {
+			version = osReleaseProperties["ORACLE_SUPPORT_PRODUCT_VERSION"]
+		}
+
+		if version != "" {
+			return true, LinuxDistro{
+				Name:       "Oracle Linux",
+				ID:         "ol",
+				Version:    version,
+				LsbRelease: lsbProperties,
+				OsRelease:  osReleaseProperties,
+			}
 		}
 	}
 
-	exists, contents := readFileFunc("/etc/oracle-release")
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsOracleLinux", "/etc/oracle-release")...)
 	if exists {
 		matched, version := parseRedhatReleaseContents(contents, "Oracle Linux")
 		if matched {
+			version = platformVersion(version, osReleaseProperties)
 			return true, LinuxDistro{
 				Name:       "Oracle Linux",
 				ID:         "ol",
@@ -427,11 +583,45 @@ func IsOracleLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDeta
 		}
 	}
 
+	// Older releases report the mangled "Enterprise Enterprise" ID in lsb-release
+	if oracleLsbIDs[lsbProperties["DISTRIB_ID"]] && lsbProperties["DISTRIB_RELEASE"] != "" {
+		return true, LinuxDistro{
+			Name:       "Oracle Linux",
+			ID:         "ol",
+			Version:    lsbProperties["DISTRIB_RELEASE"],
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
 	return false, LinuxDistro{}
 }
 
-func IsPhoton(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "photon" && osReleaseProperties["VERSION_ID"] != "" {
+// oracleLsbIDs are the lsb-release DISTRIB_ID values reported by Oracle Linux.
+var oracleLsbIDs = map[string]bool{
+	"OracleServer":               true,
+	"EnterpriseEnterpriseServer": true,
+}
+
+func isParabola(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) != "parabola" {
+		return false, LinuxDistro{}
+	}
+
+	return true, LinuxDistro{
+		Name:       "Parabola GNU/Linux-libre",
+		ID:         "parabola",
+		Version:    "rolling",
+		LsbRelease: lsbProperties,
+		OsRelease:  osReleaseProperties,
+	}
+}
+
+// photonVersion matches the version in the first line of /etc/photon-release (eg VMware Photon OS 4.0).
+var photonVersion = regexp.MustCompile("Photon (?:OS|Linux) ([0-9.]+)")
+
+func isPhoton(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "photon" && osReleaseProperties["VERSION_ID"] != "" {
 		return true, LinuxDistro{
 			Name:       "VMware Photon",
 			ID:         "photon",
@@ -441,10 +631,17 @@ func IsPhoton(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 		}
 	}
 
-	exists, contents := readFileFunc("/etc/photon-release")
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsPhoton", "/etc/photon-release")...)
 	if exists {
-		matched, version := parseRedhatReleaseContents(contents, "VMware Photon Linux")
-		if matched {
+		// The first line of the release file is "VMware Photon OS 4.0" ("VMware Photon Linux 1.0" on
+		// older releases) followed by the build number
+		firstLine := strings.SplitN(contents, "\n", 2)[0]
+		if strings.HasPrefix(firstLine, "VMware Photon") {
+			var version string
+			if matches := photonVersion.FindStringSubmatch(firstLine); len(matches) == 2 {
+				version = matches[1]
+			}
+
 			return true, LinuxDistro{
 				Name:       "VMware Photon",
 				ID:         "photon",
@@ -458,8 +655,10 @@ func IsPhoton(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 	return false, LinuxDistro{}
 }
 
-func IsPuppy(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if lsbProperties["DISTRIB_ID"] != "Puppy" {
+func isPuppy(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	// The os-release ID of Puppy is suffixed with the name of the puppy (eg puppy_fossapup64)
+	id := osReleaseID(osReleaseProperties)
+	if lsbProperties["DISTRIB_ID"] != "Puppy" && id != "puppy" && !strings.HasPrefix(id, "puppy_") {
 		return false, LinuxDistro{}
 	}
 
@@ -472,34 +671,109 @@ func IsPuppy(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (
 	}
 }
 
-func IsMageia(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "mageia" {
+func isLibertyLinux(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	isLiberty := osReleaseID(osReleaseProperties) == "liberty"
+
+	// Liberty Linux may keep the Red Hat ID while pointing its support metadata at SUSE
+	if !isLiberty && osReleaseID(osReleaseProperties) == "rhel" {
+		supportProduct := osReleaseProperties["REDHAT_SUPPORT_PRODUCT"]
+		isLiberty = strings.Contains(supportProduct, "Liberty") ||
+			strings.Contains(osReleaseProperties["SUPPORT_URL"], "suse.com") ||
+			strings.Contains(osReleaseProperties["BUG_REPORT_URL"], "suse.com")
+	}
+
+	if !isLiberty {
+		return false, LinuxDistro{}
+	}
+
+	return true, LinuxDistro{
+		Name:       "SUSE Liberty Linux",
+		ID:         "liberty",
+		Version:    osReleaseProperties["VERSION_ID"],
+		LsbRelease: lsbProperties,
+		OsRelease:  osReleaseProperties,
+	}
+}
+
+func isMageia(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "mageia" {
+		version := osReleaseProperties["VERSION_ID"]
+		if version == "" {
+			version = osReleaseProperties["VERSION"]
+		}
+
 		return true, LinuxDistro{
 			Name:       "Mageia",
 			ID:         "mageia",
-			Version:    osReleaseProperties["VERSION"],
+			Version:    version,
 			LsbRelease: lsbProperties,
 			OsRelease:  osReleaseProperties,
 		}
 	}
+
+	// Older releases of Mageia only shipped with /etc/lsb-release
+	if osReleaseID(osReleaseProperties) == "" && lsbProperties["DISTRIB_ID"] == "Mageia" {
+		return true, LinuxDistro{
+			Name:       "Mageia",
+			ID:         "mageia",
+			Version:    lsbProperties["DISTRIB_RELEASE"],
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
 	return false, LinuxDistro{}
 }
 
-func IsMint(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if lsbProperties["DISTRIB_ID"] != "LinuxMint" {
+func isMandriva(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsMandriva", "/etc/mandriva-release", "/etc/mandrake-release")...)
+	if exists {
+		// Mandrake was renamed to Mandriva, so we accept the prefix of either name
+		matched, version := parseRedhatReleaseContents(contents, "Mandr")
+		if matched {
+			return true, LinuxDistro{
+				Name:       "Mandriva Linux",
+				ID:         "mandriva",
+				Version:    version,
+				LsbRelease: lsbProperties,
+				OsRelease:  osReleaseProperties,
+			}
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+func isMint(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if lsbProperties["DISTRIB_ID"] != "LinuxMint" && osReleaseID(osReleaseProperties) != "linuxmint" {
 		return false, LinuxDistro{}
 	}
 
+	version := lsbProperties["DISTRIB_RELEASE"]
+	if version == "" {
+		version = osReleaseProperties["VERSION_ID"]
+	}
+
 	return true, LinuxDistro{
 		Name:       "Linux Mint",
 		ID:         "linuxmint",
-		Version:    lsbProperties["DISTRIB_RELEASE"],
+		Version:    version,
 		LsbRelease: lsbProperties,
 		OsRelease:  osReleaseProperties,
 	}
 }
 
-func IsMXLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+func isMXLinux(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "mx" && osReleaseProperties["VERSION_ID"] != "" {
+		return true, LinuxDistro{
+			Name:       "MX Linux",
+			ID:         "mx",
+			Version:    osReleaseProperties["VERSION_ID"],
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
 	if lsbProperties["DISTRIB_ID"] == "MX" {
 		return true, LinuxDistro{
 			Name:       "MX Linux",
@@ -510,7 +784,7 @@ func IsMXLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 		}
 	}
 
-	exists, content := readFileFunc("/etc/mx-version")
+	exists, content := d.readNonEmptyFile(d.candidatePaths("IsMXLinux", "/etc/mx-version")...)
 	if exists {
 		rex := regexp.MustCompile("(\\S+)-([0-9.]+)")
 		match := rex.FindStringSubmatch(content)
@@ -529,13 +803,31 @@ func IsMXLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails)
 	return false, LinuxDistro{}
 }
 
-func IsNovellOES(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	exists, contents := readFileFunc("/etc/novell-release")
+func isNethServer(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsNethServer", "/etc/nethserver-release")...)
+	if exists {
+		matched, version := parseRedhatReleaseContents(contents, "NethServer")
+		if matched {
+			return true, LinuxDistro{
+				Name:       "NethServer",
+				ID:         "nethserver",
+				Version:    version,
+				LsbRelease: lsbProperties,
+				OsRelease:  osReleaseProperties,
+			}
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+func isNovellOES(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsNovellOES", "/etc/novell-release")...)
 	if exists {
 		if strings.HasPrefix(contents, "Novell Open Enterprise Server") {
 			var version string
 			releaseDetails, err := parseOSRelease(strings.NewReader(contents))
-			if err == nil {
+			if err == nil && releaseDetails["VERSION"] != "" {
 				version = releaseDetails["VERSION"]
 			} else {
 				version = "unknown"
@@ -554,8 +846,8 @@ func IsNovellOES(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetail
 	return false, LinuxDistro{}
 }
 
-func IsNixOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "nixos" {
+func isNixOS(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "nixos" {
 		return true, LinuxDistro{
 			Name:       "NixOS",
 			ID:         "nixos",
@@ -568,8 +860,45 @@ func IsNixOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (
 	return false, LinuxDistro{}
 }
 
-func IsRancherOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "rancheros" {
+func isRaspberryPiOS(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	id := osReleaseID(osReleaseProperties)
+	if id != "raspbian" && id != "debian" && id != "" {
+		return false, LinuxDistro{}
+	}
+
+	// The 64-bit releases of Raspberry Pi OS identify themselves as Debian in os-release, so we look
+	// for the rpi-issue file written by the image build or the Raspbian package repository
+	if id != "raspbian" {
+		rpiIssueExists, _ := d.readNonEmptyFile(d.candidatePaths("IsRaspberryPiOS", "/etc/rpi-issue")...)
+		if !rpiIssueExists {
+			sourcesExists, sources := d.readFile("/etc/apt/sources.list")
+			if !sourcesExists || !strings.Contains(sources, "raspbian.raspberrypi.org") {
+				return false, LinuxDistro{}
+			}
+		}
+	}
+
+	version := osReleaseProperties["VERSION_ID"]
+	if version == "" {
+		debianVersionExists, versionContents := d.readNonEmptyFile("/etc/debian_version")
+		if debianVersionExists {
+			version = strings.TrimSpace(versionContents)
+		} else {
+			version = "unknown"
+		}
+	}
+
+	return true, LinuxDistro{
+		Name:       "Raspberry Pi OS",
+		ID:         "raspbian",
+		Version:    version,
+		LsbRelease: lsbProperties,
+		OsRelease:  osReleaseProperties,
+	}
+}
+
+func isRancherOS(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "rancheros" {
 		return true, LinuxDistro{
 			Name:       "RancherOS",
 			ID:         "rancheros",
@@ -582,10 +911,10 @@ func IsRancherOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetail
 	return false, LinuxDistro{}
 }
 
-func IsRHEL(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "rhel" && osReleaseProperties["VERSION_ID"] != "" {
+func isRHEL(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "rhel" && osReleaseProperties["VERSION_ID"] != "" {
 		return true, LinuxDistro{
-			Name:       "Red Hat Enterprise Linux",
+			Name:       rhelName(osReleaseProperties),
 			ID:         "rhel",
 			Version:    osReleaseProperties["VERSION_ID"],
 			LsbRelease: lsbProperties,
@@ -593,19 +922,13 @@ func IsRHEL(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (b
 		}
 	}
 
-	// Oracle Linux tries to impersonate Red Hat, so we look to see if the oracle release file is present,
-	// if so, we know that this isn't Redhat.
-	imOracle, distro := IsOracleLinux(lsbProperties, osReleaseProperties)
-	if imOracle {
-		return imOracle, distro
-	}
-
-	exists, contents := readFileFunc("/etc/redhat-release", "/etc/redhat-version")
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsRHEL", "/etc/redhat-release", "/etc/redhat-version")...)
 	if exists {
 		matched, version := parseRedhatReleaseContents(contents, "Red Hat Enterprise Linux")
 		if matched {
+			version = platformVersion(version, osReleaseProperties)
 			return true, LinuxDistro{
-				Name:       "Red Hat Enterprise Linux",
+				Name:       rhelName(osReleaseProperties),
 				ID:         "rhel",
 				Version:    version,
 				LsbRelease: lsbProperties,
@@ -617,8 +940,18 @@ func IsRHEL(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (b
 	return false, LinuxDistro{}
 }
 
-func IsSLES(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "sles" {
+// rhelName returns the name of Red Hat Enterprise Linux including its VARIANT (eg Server or
+// Workstation) when the variant is known.
+func rhelName(osReleaseProperties ReleaseDetails) string {
+	if osReleaseProperties["VARIANT"] == "" {
+		return "Red Hat Enterprise Linux"
+	}
+
+	return "Red Hat Enterprise Linux " + osReleaseProperties["VARIANT"]
+}
+
+func isSLES(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "sles" {
 		return true, LinuxDistro{
 			Name:       "SUSE Linux",
 			ID:         "sles",
@@ -628,12 +961,12 @@ func IsSLES(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (b
 		}
 	}
 
-	exists, contents := readFileFunc("/etc/SuSE-release", "/etc/sles-release")
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsSLES", "/etc/SuSE-release", "/etc/sles-release")...)
 	if exists {
 		if strings.HasPrefix(contents, "SUSE Linux") {
 			var version string
 			releaseDetails, err := parseOSRelease(strings.NewReader(contents))
-			if err == nil {
+			if err == nil && releaseDetails["VERSION"] != "" {
 				version = releaseDetails["VERSION"]
 			} else {
 				version = "unknown"
@@ -652,15 +985,8 @@ func IsSLES(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (b
 	return false, LinuxDistro{}
 }
 
-func IsScientificLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	// Oracle Linux tries to impersonate Red Hat, so we look to see if the oracle release file is present,
-	// if so, we know that this isn't Redhat.
-	imOracle, distro := IsOracleLinux(lsbProperties, osReleaseProperties)
-	if imOracle {
-		return imOracle, distro
-	}
-
-	exists, contents := readFileFunc("/etc/sl-release", "/etc/redhat-release")
+func isScientificLinux(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsScientificLinux", "/etc/sl-release", "/etc/redhat-release")...)
 	if exists {
 		matched, version := parseRedhatReleaseContents(contents, "Scientific Linux")
 		if matched {
@@ -677,18 +1003,66 @@ func IsScientificLinux(lsbProperties ReleaseDetails, osReleaseProperties Release
 	return false, LinuxDistro{}
 }
 
-func IsSlackware(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if osReleaseProperties["ID"] == "slackware" && osReleaseProperties["VERSION_ID"] != "" {
+func isSalix(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsSalix", "/etc/salix-version")...)
+	if exists && strings.HasPrefix(contents, "Salix") {
+		return true, LinuxDistro{
+			Name:       "Salix OS",
+			ID:         "salix",
+			Version:    parseSlackwareDerivativeVersion(contents),
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+func isSerpentOS(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	var name string
+	// Serpent OS was renamed to AerynOS, but older installs still report the old ID
+	switch osReleaseID(osReleaseProperties) {
+	case "aeryn":
+		name = "AerynOS"
+	case "serpent":
+		name = "Serpent OS"
+	default:
+		return false, LinuxDistro{}
+	}
+
+	version := osReleaseProperties["VERSION_ID"]
+	if version == "" {
+		version = "rolling"
+	}
+
+	return true, LinuxDistro{
+		Name:       name,
+		ID:         osReleaseID(osReleaseProperties),
+		Version:    version,
+		LsbRelease: lsbProperties,
+		OsRelease:  osReleaseProperties,
+	}
+}
+
+func isSlackware(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "slackware" && osReleaseProperties["VERSION_ID"] != "" {
+		version := osReleaseProperties["VERSION_ID"]
+		// The development branch (-current) is identified by its codename, the date of the build is
+		// reported in BUILD_ID
+		if osReleaseProperties["VERSION_CODENAME"] == "current" {
+			version = "current"
+		}
+
 		return true, LinuxDistro{
 			Name:       "Slackware",
 			ID:         "slackware",
-			Version:    osReleaseProperties["VERSION_ID"],
+			Version:    version,
 			LsbRelease: lsbProperties,
 			OsRelease:  osReleaseProperties,
 		}
 	}
 
-	exists, contents := readFileFunc("/etc/slackware-version")
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsSlackware", "/etc/slackware-version")...)
 	if exists {
 		if !strings.HasPrefix(contents, "Slackware") {
 			return false, LinuxDistro{}
@@ -714,10 +1088,14 @@ func IsSlackware(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetail
 	return false, LinuxDistro{}
 }
 
-func IsSourceMage(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	exists, contents := readFileFunc("/etc/sourcemage-release")
+// sourceMageVersion matches the version number (eg 0.62-stable) within the grimoire label of Source Mage.
+var sourceMageVersion = regexp.MustCompile("[0-9][^\\s]*$")
+
+func isSourceMage(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsSourceMage", "/etc/sourcemage-release")...)
 	if exists {
 		version := "unknown"
+		var reportedVersion string
 
 		reader := strings.NewReader(contents)
 		scanner := bufio.NewScanner(reader)
@@ -737,9 +1115,36 @@ func IsSourceMage(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetai
 			}
 		}
 
+		// The version is reported with a label (eg Grimoire 0.62-stable), so only the version number
+		// is kept as the version and the label is kept as the reported version
+		if grimoireVersion := sourceMageVersion.FindString(version); grimoireVersion != "" && grimoireVersion != version {
+			reportedVersion = version
+			version = grimoireVersion
+		}
+
 		return true, LinuxDistro{
-			Name:       "Source Mage GNU/Linux",
-			ID:         "sourcemage",
+			Name:            "Source Mage GNU/Linux",
+			ID:              "sourcemage",
+			Version:         version,
+			ReportedVersion: reportedVersion,
+			LsbRelease:      lsbProperties,
+			OsRelease:       osReleaseProperties,
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+func isSystemRescue(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "systemrescue" {
+		version := osReleaseProperties["VERSION_ID"]
+		if version == "" {
+			version = "unknown"
+		}
+
+		return true, LinuxDistro{
+			Name:       "SystemRescue",
+			ID:         "systemrescue",
 			Version:    version,
 			LsbRelease: lsbProperties,
 			OsRelease:  osReleaseProperties,
@@ -749,22 +1154,110 @@ func IsSourceMage(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetai
 	return false, LinuxDistro{}
 }
 
-func IsUbuntu(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	if lsbProperties["DISTRIB_ID"] != "Ubuntu" {
+func isTuxedoOS(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	if osReleaseID(osReleaseProperties) == "tuxedo" {
+		return true, LinuxDistro{
+			Name:       "TUXEDO OS",
+			ID:         "tuxedo",
+			Version:    osReleaseProperties["VERSION_ID"],
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+func isUbuntu(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	// Minimal installations may only provide os-release
+	if lsbProperties["DISTRIB_ID"] != "Ubuntu" &&
+		(lsbProperties["DISTRIB_ID"] != "" || osReleaseID(osReleaseProperties) != "ubuntu") {
 		return false, LinuxDistro{}
 	}
 
 	return true, LinuxDistro{
 		Name:       "Ubuntu",
 		ID:         "ubuntu",
-		Version:    lsbProperties["DISTRIB_RELEASE"],
+		Version:    ubuntuVersion(lsbProperties, osReleaseProperties),
+		IsLTS:      isUbuntuLTS(lsbProperties, osReleaseProperties),
 		LsbRelease: lsbProperties,
 		OsRelease:  osReleaseProperties,
 	}
 }
 
-func IsYellowDog(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
-	exists, contents := readFileFunc("/etc/yellowdog-release")
+// ubuntuVersion returns the numeric version of the Ubuntu release from lsb-release or os-release,
+// falling back to the version of the release codename when neither contains a numeric version.
+func ubuntuVersion(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) string {
+	if lsbProperties["DISTRIB_RELEASE"] != "" {
+		return lsbProperties["DISTRIB_RELEASE"]
+	}
+	if osReleaseProperties["VERSION_ID"] != "" {
+		return osReleaseProperties["VERSION_ID"]
+	}
+
+	for _, codename := range []string{lsbProperties["DISTRIB_CODENAME"], osReleaseProperties["VERSION_CODENAME"],
+		osReleaseProperties["UBUNTU_CODENAME"]} {
+		if version := codenameVersion("ubuntu", codename); version != "" {
+			return version
+		}
+	}
+
+	return ""
+}
+
+// isUbuntuLTS returns true when the Ubuntu release is a long term support release. Releases mention
+// LTS in their version description, otherwise the April (.04) releases of even years are LTS.
+func isUbuntuLTS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) bool {
+	if strings.Contains(osReleaseProperties["VERSION"], "LTS") ||
+		strings.Contains(lsbProperties["DISTRIB_DESCRIPTION"], "LTS") {
+		return true
+	}
+
+	version := osReleaseProperties["VERSION_ID"]
+	if version == "" {
+		version = ubuntuVersion(lsbProperties, osReleaseProperties)
+	}
+
+	segments := strings.Split(version, ".")
+	if len(segments) < 2 || segments[1] != "04" {
+		return false
+	}
+	year, err := strconv.Atoi(segments[0])
+
+	return err == nil && year%2 == 0
+}
+
+func isYocto(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	id := osReleaseID(osReleaseProperties)
+	if id == "" {
+		return false, LinuxDistro{}
+	}
+
+	for _, yoctoId := range YoctoDistroIds {
+		if id != yoctoId {
+			continue
+		}
+
+		// Yocto images are named by whoever builds them, so we trust the os-release name
+		name := osReleaseProperties["NAME"]
+		if name == "" {
+			name = id
+		}
+
+		return true, LinuxDistro{
+			Name:       name,
+			ID:         id,
+			Version:    osReleaseProperties["VERSION_ID"],
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+func isYellowDog(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsYellowDog", "/etc/yellowdog-release")...)
 	if exists {
 		matched, version := parseRedhatReleaseContents(contents, "Yellow Dog Linux")
 		if matched {
@@ -780,3 +1273,29 @@ func IsYellowDog(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetail
 
 	return false, LinuxDistro{}
 }
+
+func isZenwalk(d *Detector, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	exists, contents := d.readNonEmptyFile(d.candidatePaths("IsZenwalk", "/etc/zenwalk-version")...)
+	if exists && strings.HasPrefix(contents, "Zenwalk") {
+		return true, LinuxDistro{
+			Name:       "Zenwalk",
+			ID:         "zenwalk",
+			Version:    parseSlackwareDerivativeVersion(contents),
+			LsbRelease: lsbProperties,
+			OsRelease:  osReleaseProperties,
+		}
+	}
+
+	return false, LinuxDistro{}
+}
+
+// parseSlackwareDerivativeVersion parses the version from the contents of a Slackware derivative's
+// version file (eg "Salix 15.0" or "Zenwalk Linux 7.0") by taking its last field.
+func parseSlackwareDerivativeVersion(contents string) string {
+	fields := strings.Fields(contents)
+	if len(fields) < 2 {
+		return "unknown"
+	}
+
+	return fields[len(fields)-1]
+}
@@ -0,0 +1,225 @@
+package linux
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsOpenSuSEWithSLESReleaseFile(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/SuSE-release": "SUSE Linux Enterprise Server 11 (x86_64)\nVERSION = 11\nPATCHLEVEL = 4\n",
+	})
+
+	detectorDoesNotMatch(t, IsOpenSuSE)
+}
+
+func TestIsSLESWithOpenSuSEReleaseFile(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/SuSE-release": "openSUSE 13.2 (x86_64)\nVERSION = 13.2\nCODENAME = Harlequin\n",
+	})
+
+	detectorDoesNotMatch(t, IsSLES)
+}
+
+func TestIsSLESWithoutVersion(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/SuSE-release": "SUSE Linux Enterprise Server 11 (x86_64)\n",
+	})
+
+	detected, distro := isSLES(NewDetector(), ReleaseDetails{}, ReleaseDetails{})
+	if !detected {
+		t.Fatal("SLES was not detected")
+	}
+	if distro.Version != "unknown" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (unknown) was (%s).", distro.Version)
+	}
+}
+
+func TestIsNovellOESWithSLESReleaseFile(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/novell-release": "SUSE Linux Enterprise Server 11 (x86_64)\nVERSION = 11\n",
+	})
+
+	detectorDoesNotMatch(t, IsNovellOES)
+}
+
+func TestIsSourceMageWithoutReleaseFile(t *testing.T) {
+	overrideReadFile(t, map[string]string{})
+
+	detectorDoesNotMatch(t, IsSourceMage)
+}
+
+func TestIsCruxWithoutCruxScript(t *testing.T) {
+	overrideReadFile(t, map[string]string{})
+
+	detectorDoesNotMatch(t, IsCrux)
+}
+
+func TestIsCentOSWithFedoraReleaseFile(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/redhat-release": "Fedora release 33 (Thirty Three)\n",
+	})
+
+	detectorDoesNotMatch(t, IsCentOS)
+}
+
+func TestIsRHELWithUnparsableReleaseFile(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/redhat-release": "garbage\n",
+	})
+
+	detectorDoesNotMatch(t, IsRHEL)
+}
+
+func TestIsRHELWithCentOSReleaseFile(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/etc/centos-release": "CentOS Linux release 7.9.2009 (Core)\n",
+		"/etc/redhat-release": "CentOS Linux release 7.9.2009 (Core)\n",
+	})
+	osReleaseProperties := ReleaseDetails{
+		"NAME":       "Red Hat Enterprise Linux Server",
+		"ID":         "rhel",
+		"ID_LIKE":    "fedora",
+		"VERSION_ID": "7.9",
+	}
+
+	detector := NewDetector()
+	detector.collectWarnings = true
+	distro := detector.discoverDistroFromProperties(ReleaseDetails{}, osReleaseProperties)
+	if distro.ID != "centos" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (centos) was (%s).", distro.ID)
+	}
+	if distro.Version != "7.9.2009" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (7.9.2009) was (%s).", distro.Version)
+	}
+	if distro.ReportedID != "rhel" {
+		t.Errorf("reported id was not recorded correctly. Expected (rhel) was (%s).", distro.ReportedID)
+	}
+	if len(detector.warnings) != 1 || !strings.Contains(detector.warnings[0], "CentOS Linux release 7.9.2009") {
+		t.Errorf("no warning was recorded for the mismatched release files: %v", detector.warnings)
+	}
+}
+
+func TestIsBusyBoxWithOtherBinary(t *testing.T) {
+	overrideReadBinaryFile(t, "/bin/true", "\x7fELF\x02\x01\x01GNU coreutils 9.1 true\x00Usage: %s [ignored command line arguments]\x00")
+
+	detectorDoesNotMatch(t, IsBusyBox)
+}
+
+func TestIsBusyBoxWithFormatString(t *testing.T) {
+	overrideReadBinaryFile(t, "/bin/true", "\x7fELF\x02\x01\x01BusyBox v%s (%s)\x00")
+
+	detectorDoesNotMatch(t, IsBusyBox)
+}
+
+func TestIsBusyBoxWithUnalignedVersion(t *testing.T) {
+	overrideReadBinaryFile(t, "/bin/true", "\x7fELF\x02\x01\x01\x00BBusy BusyBox v1.2.3 (2023-01-01 00:00:00 UTC)\x00")
+
+	detected, distro := isBusyBox(NewDetector(), ReleaseDetails{}, ReleaseDetails{})
+	if !detected {
+		t.Fatal("BusyBox was not detected")
+	}
+	if distro.Version != "v1.2.3" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (v1.2.3) was (%s).", distro.Version)
+	}
+}
+
+func TestIsOracleLinuxWithUpperCaseId(t *testing.T) {
+	osReleaseProperties := ReleaseDetails{
+		"NAME":       "Oracle Linux Server",
+		"ID":         "OL",
+		"VERSION_ID": "8.9",
+	}
+
+	detected, distro := isOracleLinux(NewDetector(), ReleaseDetails{}, osReleaseProperties)
+	if !detected {
+		t.Fatal("Oracle Linux was not detected")
+	}
+	if distro.ID != "ol" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (ol) was (%s).", distro.ID)
+	}
+}
+
+func TestIsAlpineWithCandidatePaths(t *testing.T) {
+	overrideReadFile(t, map[string]string{
+		"/mnt/alpine-release": "3.18.4\n",
+	})
+
+	detector := NewDetector()
+	detector.CandidatePaths = map[string][]string{
+		"IsAlpine": {"/mnt/alpine-release"},
+	}
+	detected, distro := isAlpine(detector, ReleaseDetails{}, ReleaseDetails{})
+	if !detected {
+		t.Fatal("Alpine was not detected")
+	}
+	if distro.Version != "3.18.4" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (3.18.4) was (%s).", distro.Version)
+	}
+}
+
+func TestFileBasedDetectorsWithEmptyReleaseFiles(t *testing.T) {
+	detectors := []func(ReleaseDetails, ReleaseDetails) (bool, LinuxDistro){
+		IsAlpine, IsAndroid, IsCentOS, IsClearOS, IsClonezilla, IsCrux, IsDebian, IsFedora, IsOpenSuSE,
+		IsOracleLinux, IsPhoton, IsMandriva, IsMXLinux, IsNethServer, IsNovellOES, IsRHEL, IsSLES,
+		IsRaspberryPiOS, IsScientificLinux, IsSalix, IsSlackware, IsSourceMage, IsYellowDog, IsZenwalk,
+	}
+
+	for _, contents := range []string{"", " \n"} {
+		originalReadFileFunc := readFileFunc
+		readFileFunc = func(_ *Detector, _ ...string) (bool, string) {
+			return true, contents
+		}
+
+		for _, detector := range detectors {
+			detectorDoesNotMatch(t, detector)
+		}
+
+		readFileFunc = originalReadFileFunc
+	}
+}
+
+// overrideReadFile replaces readFileFunc for the duration of the test with a function that returns the
+// contents of the first of the requested paths present in files.
+func overrideReadFile(t *testing.T, files map[string]string) {
+	originalReadFileFunc := readFileFunc
+	readFileFunc = func(_ *Detector, filePaths ...string) (bool, string) {
+		for _, filePath := range filePaths {
+			if contents, ok := files[filePath]; ok {
+				return true, contents
+			}
+		}
+
+		return false, ""
+	}
+	t.Cleanup(func() {
+		readFileFunc = originalReadFileFunc
+	})
+}
+
+// overrideReadBinaryFile replaces readBinaryFileFunc for the duration of the test with a function that
+// returns the supplied contents for the supplied path.
+func overrideReadBinaryFile(t *testing.T, filePath string, contents string) {
+	originalReadBinaryFileFunc := readBinaryFileFunc
+	readBinaryFileFunc = func(_ *Detector, filePaths []string) (io.ReadCloser, string, error) {
+		if reflect.DeepEqual(filePaths, []string{filePath}) {
+			return ioutil.NopCloser(strings.NewReader(contents)), filePath, nil
+		}
+
+		return nil, "", os.ErrNotExist
+	}
+	t.Cleanup(func() {
+		readBinaryFileFunc = originalReadBinaryFileFunc
+	})
+}
+
+func detectorDoesNotMatch(t *testing.T, detector func(ReleaseDetails, ReleaseDetails) (bool, LinuxDistro)) {
+	detected, distro := detector(ReleaseDetails{}, ReleaseDetails{})
+	if detected {
+		t.Errorf("%s unexpectedly detected the distro (%s)", getFunctionName(detector), distro.ID)
+	}
+}
@@ -0,0 +1,293 @@
+package linux
+
+// The exported distro tests inspect the filesystem at FileSystemRoot (see NewDetector). A Detector runs
+// the detector aware form of each test from distroTestTable instead, so that files are read from the
+// detector's root.
+
+func IsAlpine(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsAlpine", lsbProperties, osReleaseProperties)
+}
+
+func IsAlt(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsAlt", lsbProperties, osReleaseProperties)
+}
+
+func IsAmazonLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsAmazonLinux", lsbProperties, osReleaseProperties)
+}
+
+func IsAndroid(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsAndroid", lsbProperties, osReleaseProperties)
+}
+
+func IsArchLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsArchLinux", lsbProperties, osReleaseProperties)
+}
+
+func IsBusyBox(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsBusyBox", lsbProperties, osReleaseProperties)
+}
+
+func IsCentOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsCentOS", lsbProperties, osReleaseProperties)
+}
+
+func IsChromeOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsChromeOS", lsbProperties, osReleaseProperties)
+}
+
+func IsClearLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsClearLinux", lsbProperties, osReleaseProperties)
+}
+
+func IsClearOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsClearOS", lsbProperties, osReleaseProperties)
+}
+
+func IsClonezilla(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsClonezilla", lsbProperties, osReleaseProperties)
+}
+
+func IsCrux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsCrux", lsbProperties, osReleaseProperties)
+}
+
+func IsDebian(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsDebian", lsbProperties, osReleaseProperties)
+}
+
+func IsFedora(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsFedora", lsbProperties, osReleaseProperties)
+}
+
+func IsFreespire(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsFreespire", lsbProperties, osReleaseProperties)
+}
+
+func IsKali(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsKali", lsbProperties, osReleaseProperties)
+}
+
+func IsGentoo(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsGentoo", lsbProperties, osReleaseProperties)
+}
+
+func IsHyperbola(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsHyperbola", lsbProperties, osReleaseProperties)
+}
+
+func IsOpenSuSE(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsOpenSuSE", lsbProperties, osReleaseProperties)
+}
+
+func IsOracleLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsOracleLinux", lsbProperties, osReleaseProperties)
+}
+
+func IsParabola(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsParabola", lsbProperties, osReleaseProperties)
+}
+
+func IsPhoton(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsPhoton", lsbProperties, osReleaseProperties)
+}
+
+func IsPuppy(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsPuppy", lsbProperties, osReleaseProperties)
+}
+
+func IsLibertyLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsLibertyLinux", lsbProperties, osReleaseProperties)
+}
+
+func IsMageia(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsMageia", lsbProperties, osReleaseProperties)
+}
+
+func IsMandriva(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsMandriva", lsbProperties, osReleaseProperties)
+}
+
+func IsMint(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsMint", lsbProperties, osReleaseProperties)
+}
+
+func IsMXLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsMXLinux", lsbProperties, osReleaseProperties)
+}
+
+func IsNethServer(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsNethServer", lsbProperties, osReleaseProperties)
+}
+
+func IsNovellOES(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsNovellOES", lsbProperties, osReleaseProperties)
+}
+
+func IsNixOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsNixOS", lsbProperties, osReleaseProperties)
+}
+
+func IsRaspberryPiOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsRaspberryPiOS", lsbProperties, osReleaseProperties)
+}
+
+func IsRancherOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsRancherOS", lsbProperties, osReleaseProperties)
+}
+
+func IsRHEL(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsRHEL", lsbProperties, osReleaseProperties)
+}
+
+func IsSLES(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsSLES", lsbProperties, osReleaseProperties)
+}
+
+func IsScientificLinux(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsScientificLinux", lsbProperties, osReleaseProperties)
+}
+
+func IsSalix(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsSalix", lsbProperties, osReleaseProperties)
+}
+
+func IsSerpentOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsSerpentOS", lsbProperties, osReleaseProperties)
+}
+
+func IsSlackware(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsSlackware", lsbProperties, osReleaseProperties)
+}
+
+func IsSourceMage(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsSourceMage", lsbProperties, osReleaseProperties)
+}
+
+func IsSystemRescue(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsSystemRescue", lsbProperties, osReleaseProperties)
+}
+
+func IsTuxedoOS(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsTuxedoOS", lsbProperties, osReleaseProperties)
+}
+
+func IsUbuntu(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsUbuntu", lsbProperties, osReleaseProperties)
+}
+
+func IsYocto(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsYocto", lsbProperties, osReleaseProperties)
+}
+
+func IsYellowDog(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsYellowDog", lsbProperties, osReleaseProperties)
+}
+
+func IsZenwalk(lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runDistroTest("IsZenwalk", lsbProperties, osReleaseProperties)
+}
+
+// distroTest pairs the name of an exported distro test with its detector aware form. The tests named
+// in precededBy detect distros that impersonate this one, so they are run first when the exported test
+// is called on its own. A Detector gets the same effect from the order of distroTestTable.
+type distroTest struct {
+	name       string
+	detect     func(*Detector, ReleaseDetails, ReleaseDetails) (bool, LinuxDistro)
+	precededBy []string
+}
+
+// distroTestTable lists the distro tests in the order in which a Detector runs them.
+var distroTestTable = []distroTest{
+	// Oracle Linux impersonates Red Hat, so it is ruled out once before the Red Hat family
+	{name: "IsOracleLinux", detect: isOracleLinux},
+	{name: "IsNethServer", detect: isNethServer},
+	{name: "IsClearOS", detect: isClearOS},
+	// NethServer and ClearOS are built on CentOS and keep its release files
+	{name: "IsCentOS", detect: isCentOS, precededBy: []string{"IsOracleLinux", "IsNethServer", "IsClearOS"}},
+	{name: "IsLibertyLinux", detect: isLibertyLinux},
+	// SUSE Liberty Linux can keep the Red Hat os-release ID, and systems converted from CentOS may keep an
+	// os-release file that doesn't match the release file (or vice versa), so the CentOS release file is
+	// treated as the truth
+	{name: "IsRHEL", detect: isRHEL, precededBy: []string{"IsOracleLinux", "IsLibertyLinux", "IsCentOS"}},
+	{name: "IsChromeOS", detect: isChromeOS},
+	{name: "IsTuxedoOS", detect: isTuxedoOS},
+	// Chrome OS environments (eg crouton) may merge the Ubuntu lsb-release keys with the Chrome OS keys,
+	// and TUXEDO OS keeps the Ubuntu lsb-release file
+	{name: "IsUbuntu", detect: isUbuntu, precededBy: []string{"IsChromeOS", "IsTuxedoOS"}},
+	{name: "IsClonezilla", detect: isClonezilla},
+	{name: "IsFreespire", detect: isFreespire},
+	{name: "IsRaspberryPiOS", detect: isRaspberryPiOS},
+	{name: "IsMXLinux", detect: isMXLinux},
+	// MX Linux does a good job of impersonating Debian, Clonezilla Live is built on top of Debian and
+	// Raspberry Pi OS may identify itself as Debian
+	{name: "IsDebian", detect: isDebian, precededBy: []string{"IsMXLinux", "IsClonezilla", "IsRaspberryPiOS"}},
+	{name: "IsAmazonLinux", detect: isAmazonLinux},
+	{name: "IsFedora", detect: isFedora, precededBy: []string{"IsOracleLinux"}},
+	{name: "IsOpenSuSE", detect: isOpenSuSE},
+	{name: "IsSLES", detect: isSLES},
+	{name: "IsPhoton", detect: isPhoton},
+	{name: "IsAlpine", detect: isAlpine},
+	{name: "IsSystemRescue", detect: isSystemRescue},
+	{name: "IsParabola", detect: isParabola},
+	{name: "IsHyperbola", detect: isHyperbola},
+	{name: "IsArchLinux", detect: isArchLinux},
+	{name: "IsGentoo", detect: isGentoo},
+	{name: "IsKali", detect: isKali},
+	{name: "IsScientificLinux", detect: isScientificLinux, precededBy: []string{"IsOracleLinux"}},
+	{name: "IsSalix", detect: isSalix},
+	{name: "IsZenwalk", detect: isZenwalk},
+	// Salix and Zenwalk are derived from Slackware and keep its release files
+	{name: "IsSlackware", detect: isSlackware, precededBy: []string{"IsSalix", "IsZenwalk"}},
+	{name: "IsSerpentOS", detect: isSerpentOS},
+	{name: "IsMageia", detect: isMageia},
+	{name: "IsMandriva", detect: isMandriva},
+	{name: "IsClearLinux", detect: isClearLinux},
+	{name: "IsMint", detect: isMint},
+	{name: "IsNovellOES", detect: isNovellOES},
+	{name: "IsPuppy", detect: isPuppy},
+	{name: "IsRancherOS", detect: isRancherOS},
+	{name: "IsNixOS", detect: isNixOS},
+	{name: "IsAlt", detect: isAlt},
+	{name: "IsCrux", detect: isCrux},
+	{name: "IsSourceMage", detect: isSourceMage},
+	{name: "IsAndroid", detect: isAndroid},
+	{name: "IsYellowDog", detect: isYellowDog},
+	{name: "IsYocto", detect: isYocto},
+	{name: "IsBusyBox", detect: isBusyBox},
+}
+
+var distroTestsByName = indexDistroTests(distroTestTable)
+
+func indexDistroTests(distroTests []distroTest) map[string]distroTest {
+	index := make(map[string]distroTest, len(distroTests))
+	for _, test := range distroTests {
+		index[test.name] = test
+	}
+
+	return index
+}
+
+// runDistroTest runs the named distro test against the filesystem at FileSystemRoot after ruling out
+// the distros that impersonate it.
+func runDistroTest(name string, lsbProperties ReleaseDetails, osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	return runPrecededDistroTest(NewDetector(), distroTestsByName[name], map[string]bool{},
+		lsbProperties, osReleaseProperties)
+}
+
+func runPrecededDistroTest(d *Detector, test distroTest, ran map[string]bool, lsbProperties ReleaseDetails,
+	osReleaseProperties ReleaseDetails) (bool, LinuxDistro) {
+	for _, name := range test.precededBy {
+		if ran[name] {
+			continue
+		}
+		ran[name] = true
+
+		if wasDetected, distro := runPrecededDistroTest(d, distroTestsByName[name], ran, lsbProperties,
+			osReleaseProperties); wasDetected {
+			return wasDetected, distro
+		}
+	}
+
+	return test.detect(d, lsbProperties, osReleaseProperties)
+}
@@ -0,0 +1,46 @@
+package linux
+
+import "strings"
+
+// variantEditions maps the os-release VARIANT_IDs of distros (eg RHEL Server or Fedora Workstation) to
+// the edition of the distro.
+var variantEditions = map[string]string{
+	"server":      "server",
+	"workstation": "desktop",
+	"desktop":     "desktop",
+	"cloud":       "cloud",
+	"minimal":     "minimal",
+}
+
+// ubuntuEditionMarkers are the dpkg file lists of the Ubuntu metapackages that identify the edition of
+// an Ubuntu installation in order of precedence.
+var ubuntuEditionMarkers = []struct {
+	path    string
+	edition string
+}{
+	{path: "/var/lib/dpkg/info/ubuntu-desktop.list", edition: "desktop"},
+	{path: "/var/lib/dpkg/info/ubuntu-desktop-minimal.list", edition: "desktop"},
+	{path: "/var/lib/dpkg/info/ubuntu-server.list", edition: "server"},
+}
+
+// edition returns the edition of the distro (server, desktop, cloud or minimal) based on the VARIANT_ID
+// in os-release. An empty string is returned when the distro doesn't report a known variant.
+func (l *LinuxDistro) edition() string {
+	return variantEditions[strings.ToLower(l.OsRelease["VARIANT_ID"])]
+}
+
+// detectEdition returns the edition of the supplied distro based on the packages installed when the
+// distro doesn't report its edition in os-release. Only Ubuntu is supported.
+func (d *Detector) detectEdition(distro LinuxDistro) string {
+	if distro.Edition != "" || distro.ID != "ubuntu" {
+		return distro.Edition
+	}
+
+	for _, marker := range ubuntuEditionMarkers {
+		if _, err := d.stat(marker.path); err == nil {
+			return marker.edition
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,83 @@
+package linux
+
+import (
+	"strings"
+	"time"
+)
+
+// eolDateLayout is the layout of the dates in EOLDates.
+const eolDateLayout = "2006-01-02"
+
+// EOLDates maps distro IDs to the date (YYYY-MM-DD) at which standard support ended or will end for
+// each version of the distro. Versions are either the full version (eg 20.04 for Ubuntu) or the
+// major version (eg 8 for CentOS 8.5). Append to the map to track other distros.
+var EOLDates = map[string]map[string]string{
+	"centos": {
+		"6": "2020-11-30",
+		"7": "2024-06-30",
+		"8": "2021-12-31",
+	},
+	"debian": {
+		"8":  "2020-06-30",
+		"9":  "2022-06-30",
+		"10": "2024-06-30",
+		"11": "2026-08-31",
+		"12": "2028-06-30",
+	},
+	"ol": {
+		"6": "2021-03-01",
+		"7": "2024-12-31",
+		"8": "2029-07-31",
+		"9": "2032-06-30",
+	},
+	"rhel": {
+		"6": "2020-11-30",
+		"7": "2024-06-30",
+		"8": "2029-05-31",
+		"9": "2032-05-31",
+	},
+	"ubuntu": {
+		"16.04": "2021-04-30",
+		"18.04": "2023-05-31",
+		"20.04": "2025-05-31",
+		"22.04": "2027-06-01",
+		"24.04": "2029-05-31",
+	},
+}
+
+// EOLDate returns the date at which standard support for the distro version ends and whether the
+// date is known.
+func (l *LinuxDistro) EOLDate() (time.Time, bool) {
+	versions, ok := EOLDates[l.ID]
+	if !ok {
+		// The ID may have been normalized (see Detector.NormalizeIDs)
+		versions, ok = EOLDates[l.ReportedID]
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+
+	version := l.NormalizedVersion()
+	date, ok := versions[version]
+	if !ok {
+		major := strings.SplitN(version, ".", 2)[0]
+		date, ok = versions[major]
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+
+	eolDate, err := time.Parse(eolDateLayout, date)
+	if err != nil {
+		LogWarnf("invalid end of life date (%s) for %s %s: %v", date, l.ID, version, err)
+		return time.Time{}, false
+	}
+
+	return eolDate, true
+}
+
+// IsEOL returns true when the distro version is known to have reached the end of standard support.
+func (l *LinuxDistro) IsEOL() bool {
+	eolDate, ok := l.EOLDate()
+	return ok && time.Now().After(eolDate)
+}
@@ -0,0 +1,242 @@
+package linux
+
+import (
+	"strings"
+)
+
+// kernelVersionDistros maps strings found in the compiler details of /proc/version to the distro that
+// built the kernel. Ubuntu is listed before Debian because Ubuntu kernel strings mention both.
+var kernelVersionDistros = []struct {
+	marker string
+	id     string
+	name   string
+}{
+	{marker: "Ubuntu", id: "ubuntu", name: "Ubuntu"},
+	{marker: "Debian", id: "debian", name: "Debian GNU/Linux"},
+	{marker: "Red Hat", id: "rhel", name: "Red Hat Enterprise Linux"},
+	{marker: "SUSE", id: "sles", name: "SUSE Linux"},
+}
+
+// guessFromKernelVersion attempts to identify the distro from the kernel build string in /proc/version,
+// which is often retained by stripped images that have lost their release files.
+func guessFromKernelVersion(d *Detector) (bool, string, string) {
+	exists, contents := d.readFile("/proc/version")
+	if !exists || !strings.HasPrefix(contents, "Linux version") {
+		return false, "", ""
+	}
+
+	for _, distro := range kernelVersionDistros {
+		if strings.Contains(contents, "("+distro.marker) {
+			return true, distro.id, distro.name
+		}
+	}
+
+	return false, "", ""
+}
+
+// packageManagerPaths maps the package databases and configuration directories that are left on disk by
+// each package manager to the package manager. They are checked in order.
+var packageManagerPaths = []struct {
+	path           string
+	packageManager string
+}{
+	{path: "/var/lib/rpm", packageManager: "rpm"},
+	{path: "/usr/lib/sysimage/rpm", packageManager: "rpm"},
+	{path: "/var/lib/dpkg/status", packageManager: "dpkg"},
+	{path: "/var/lib/pacman", packageManager: "pacman"},
+	{path: "/etc/apk", packageManager: "apk"},
+}
+
+// detectPackageManagerFromFS determines the package manager used by the distro from the presence of its
+// package database. This allows the package manager to be known even when the distro can't be identified.
+func detectPackageManagerFromFS(d *Detector) string {
+	for _, candidate := range packageManagerPaths {
+		d.recordInspectedPaths([]string{candidate.path})
+		if _, err := d.stat(candidate.path); err == nil {
+			return candidate.packageManager
+		}
+	}
+
+	return ""
+}
+
+// packageManagerBinaries maps the binaries installed by each package manager to the package manager.
+// Front ends such as zypper are mapped to the package manager that they drive. They are checked in
+// order.
+var packageManagerBinaries = []struct {
+	path           string
+	packageManager string
+}{
+	{path: "/usr/bin/rpm", packageManager: "rpm"},
+	{path: "/usr/bin/dpkg", packageManager: "dpkg"},
+	{path: "/sbin/apk", packageManager: "apk"},
+	{path: "/usr/bin/pacman", packageManager: "pacman"},
+	{path: "/usr/bin/zypper", packageManager: "rpm"},
+	{path: "/usr/bin/emerge", packageManager: "portage"},
+	{path: "/usr/bin/xbps-install", packageManager: "xbps"},
+}
+
+// DetectedPackageManager returns the package manager whose binary is installed under the detector's
+// root. Unlike LinuxDistro.PackageManager, which is derived from the distro ID, the package manager
+// is verified on disk, so it is correct for images that have been stripped or repackaged. An empty
+// string is returned when no known package manager binary is installed.
+func (d *Detector) DetectedPackageManager() string {
+	for _, candidate := range packageManagerBinaries {
+		d.recordInspectedPaths([]string{candidate.path})
+		if fileInfo, err := d.stat(candidate.path); err == nil && !fileInfo.IsDir() {
+			return candidate.packageManager
+		}
+	}
+
+	return ""
+}
+
+// yumRepoDistros maps the hosts found in the base URLs of yum/dnf repository definitions to the distro
+// that publishes the repository in order of precedence. Fedora comes last because the EPEL repositories
+// that are installed on the other distros are served from fedoraproject.org.
+var yumRepoDistros = []struct {
+	host string
+	id   string
+	name string
+}{
+	{host: "rockylinux.org", id: "rocky", name: "Rocky Linux"},
+	{host: "almalinux.org", id: "almalinux", name: "AlmaLinux"},
+	{host: "oracle.com", id: "ol", name: "Oracle Linux"},
+	{host: "centos.org", id: "centos", name: "CentOS Linux"},
+	{host: "fedoraproject.org", id: "fedora", name: "Fedora"},
+}
+
+// guessFromYumRepos attempts to identify the distro from the repository definitions in /etc/yum.repos.d,
+// which often survive when the release files have been removed from an image.
+func guessFromYumRepos(d *Detector) (bool, string, string) {
+	const repoDir = "/etc/yum.repos.d"
+
+	fileNames, err := d.readDir(repoDir)
+	if err != nil {
+		return false, "", ""
+	}
+
+	// Every repository is scanned, so that the most specific distro wins regardless of the file order
+	match := len(yumRepoDistros)
+	for _, fileName := range fileNames {
+		if !strings.HasSuffix(fileName, ".repo") {
+			continue
+		}
+
+		exists, contents := d.readFile(repoDir + "/" + fileName)
+		if !exists {
+			continue
+		}
+
+		for _, line := range strings.Split(contents, "\n") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, "baseurl") && !strings.HasPrefix(line, "mirrorlist") &&
+				!strings.HasPrefix(line, "metalink") {
+				continue
+			}
+
+			for i, distro := range yumRepoDistros[:match] {
+				if strings.Contains(line, distro.host) {
+					match = i
+					break
+				}
+			}
+		}
+	}
+
+	if match == len(yumRepoDistros) {
+		return false, "", ""
+	}
+
+	return true, yumRepoDistros[match].id, yumRepoDistros[match].name
+}
+
+// redhatSupportProducts maps the products named in the os-release REDHAT_SUPPORT_PRODUCT and
+// REDHAT_BUGZILLA_PRODUCT keys to the distro.
+var redhatSupportProducts = []struct {
+	product string
+	id      string
+	name    string
+}{
+	{product: "centos", id: "centos", name: "CentOS Linux"},
+	{product: "oracle", id: "ol", name: "Oracle Linux"},
+	{product: "scientific", id: "scientific", name: "Scientific Linux"},
+	{product: "fedora", id: "fedora", name: "Fedora"},
+	{product: "red hat enterprise linux", id: "rhel", name: "Red Hat Enterprise Linux"},
+}
+
+// guessFromRedhatSupportProduct attempts to identify a Red Hat family distro from the support product
+// keys in os-release, which are kept by repackaged images that replace the ID with a generic value.
+func guessFromRedhatSupportProduct(osReleaseProperties ReleaseDetails) (bool, string, string) {
+	for _, key := range []string{"REDHAT_SUPPORT_PRODUCT", "REDHAT_BUGZILLA_PRODUCT"} {
+		product := strings.ToLower(osReleaseProperties[key])
+		if product == "" {
+			continue
+		}
+
+		for _, distro := range redhatSupportProducts {
+			if strings.HasPrefix(product, distro.product) {
+				return true, distro.id, distro.name
+			}
+		}
+	}
+
+	return false, "", ""
+}
+
+// guessFromSolarisRelease returns the first line of the Solaris style /etc/release file (eg "SmartOS
+// x86_64" or "OpenIndiana Hipster 2020.04 (powered by illumos)"). The file may be present in illumos
+// LX-branded zones that run Linux userlands without any Linux release files.
+func guessFromSolarisRelease(d *Detector) (bool, string) {
+	exists, contents := d.readFile("/etc/release")
+	if !exists {
+		return false, ""
+	}
+
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			return true, line
+		}
+	}
+
+	return false, ""
+}
+
+// efiVendorDistros maps the vendor directories that distros install their signed bootloader (shim)
+// to under /boot/efi/EFI to the distro. They are checked in order, so that distros that may also
+// leave behind the directory of the distro that they are derived from are listed first.
+var efiVendorDistros = []struct {
+	vendor string
+	id     string
+	name   string
+}{
+	{vendor: "rocky", id: "rocky", name: "Rocky Linux"},
+	{vendor: "almalinux", id: "almalinux", name: "AlmaLinux"},
+	{vendor: "centos", id: "centos", name: "CentOS Linux"},
+	{vendor: "fedora", id: "fedora", name: "Fedora"},
+	{vendor: "redhat", id: "rhel", name: "Red Hat Enterprise Linux"},
+	{vendor: "ubuntu", id: "ubuntu", name: "Ubuntu"},
+	{vendor: "debian", id: "debian", name: "Debian GNU/Linux"},
+	{vendor: "opensuse", id: "opensuse", name: "openSUSE"},
+	{vendor: "sles", id: "sles", name: "SUSE Linux"},
+}
+
+// guessFromEFIVendor attempts to identify the distro from the vendor directories in the EFI system
+// partition, which is typically mounted separately and survives when /etc has been wiped.
+func guessFromEFIVendor(d *Detector) (bool, string, string) {
+	vendorDirs, err := d.readSubdirs("/boot/efi/EFI")
+	if err != nil {
+		return false, "", ""
+	}
+
+	for _, distro := range efiVendorDistros {
+		for _, vendorDir := range vendorDirs {
+			if strings.ToLower(vendorDir) == distro.vendor {
+				return true, distro.id, distro.name
+			}
+		}
+	}
+
+	return false, "", ""
+}
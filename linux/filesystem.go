@@ -0,0 +1,65 @@
+package linux
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// fileSystem provides access to the files of a filesystem that replaces the OS filesystem, such as
+// an io/fs.FS (see NewFSDetector). Paths are the same absolute slash separated paths that are read
+// from the OS filesystem.
+type fileSystem interface {
+	open(filePath string) (io.ReadCloser, os.FileInfo, error)
+	stat(filePath string) (os.FileInfo, error)
+	readDir(dirPath string) ([]os.FileInfo, error)
+	glob(pattern string) ([]string, error)
+}
+
+// stat returns the file info of the supplied path relative to the detector's root.
+func (d *Detector) stat(filePath string) (os.FileInfo, error) {
+	if d.fsys != nil {
+		return d.fsys.stat(filePath)
+	}
+
+	return os.Stat(d.rootedPath(filePath))
+}
+
+// readDirInfos returns the file info of every entry in the supplied directory relative to the
+// detector's root.
+func (d *Detector) readDirInfos(dirPath string) ([]os.FileInfo, error) {
+	if d.fsys != nil {
+		return d.fsys.readDir(dirPath)
+	}
+
+	return ioutil.ReadDir(d.rootedPath(dirPath))
+}
+
+// readSubdirs returns the names of the directories in the supplied directory.
+func (d *Detector) readSubdirs(dirPath string) ([]string, error) {
+	d.recordInspectedPaths([]string{dirPath})
+
+	fileInfos, err := d.readDirInfos(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() {
+			names = append(names, fileInfo.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// glob returns the paths matching the supplied pattern relative to the detector's root.
+func (d *Detector) glob(pattern string) ([]string, error) {
+	if d.fsys != nil {
+		return d.fsys.glob(pattern)
+	}
+
+	return filepath.Glob(d.rootedPath(pattern))
+}
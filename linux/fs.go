@@ -0,0 +1,83 @@
+//go:build go1.16
+// +build go1.16
+
+package linux
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// NewFSDetector creates a new Detector that inspects the files of the supplied filesystem, such as an
+// embedded image or fstest.MapFS, rather than the OS filesystem. The paths inspected are relative to
+// the root of the filesystem (eg etc/os-release).
+func NewFSDetector(fsys fs.FS) *Detector {
+	return &Detector{
+		Root:      string(os.PathSeparator),
+		AllowExec: true,
+		fsys:      &fsFileSystem{fsys: fsys},
+	}
+}
+
+// DiscoverDistroFS detects the distro installed in the supplied filesystem.
+func DiscoverDistroFS(fsys fs.FS) LinuxDistro {
+	return NewFSDetector(fsys).DiscoverDistro()
+}
+
+// fsFileSystem is a fileSystem backed by an io/fs.FS.
+type fsFileSystem struct {
+	fsys fs.FS
+}
+
+// fsName converts an absolute path to the unrooted form of the path used by io/fs.FS.
+func fsName(filePath string) string {
+	name := strings.Trim(filePath, "/")
+	if name == "" {
+		return "."
+	}
+
+	return name
+}
+
+func (f *fsFileSystem) open(filePath string) (io.ReadCloser, os.FileInfo, error) {
+	file, err := f.fsys.Open(fsName(filePath))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+
+	return file, fileInfo, nil
+}
+
+func (f *fsFileSystem) stat(filePath string) (os.FileInfo, error) {
+	return fs.Stat(f.fsys, fsName(filePath))
+}
+
+func (f *fsFileSystem) readDir(dirPath string) ([]os.FileInfo, error) {
+	entries, err := fs.ReadDir(f.fsys, fsName(dirPath))
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		fileInfos = append(fileInfos, fileInfo)
+	}
+
+	return fileInfos, nil
+}
+
+func (f *fsFileSystem) glob(pattern string) ([]string, error) {
+	return fs.Glob(f.fsys, fsName(pattern))
+}
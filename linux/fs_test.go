@@ -0,0 +1,50 @@
+//go:build go1.16
+// +build go1.16
+
+package linux
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestDiscoverDistroFSAlpine(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/os-release": &fstest.MapFile{
+			Data: []byte("NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.12.1\nPRETTY_NAME=\"Alpine Linux v3.12\"\n"),
+		},
+		"lib/ld-musl-x86_64.so.1": &fstest.MapFile{},
+	}
+
+	distro := DiscoverDistroFS(fsys)
+	if distro.ID != "alpine" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (alpine) was (%s).", distro.ID)
+	}
+	if distro.Version != "3.12.1" {
+		t.Errorf("Linux distro version was not detected correctly. Expected (3.12.1) was (%s).", distro.Version)
+	}
+	if distro.Libc != "musl" {
+		t.Errorf("libc was not detected correctly. Expected (musl) was (%s).", distro.Libc)
+	}
+}
+
+func TestDiscoverDistroFSEmpty(t *testing.T) {
+	distro := DiscoverDistroFS(fstest.MapFS{})
+	if distro.ID != "unknown" {
+		t.Errorf("Linux distro id was not detected correctly. Expected (unknown) was (%s).", distro.ID)
+	}
+}
+
+func TestDiscoverDistroFSOSTree(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/os-release": &fstest.MapFile{
+			Data: []byte("NAME=\"CentOS Stream\"\nID=\"centos\"\nVERSION_ID=\"9\"\n"),
+		},
+		"run/ostree-booted": &fstest.MapFile{},
+	}
+
+	distro := DiscoverDistroFS(fsys)
+	if !distro.IsImmutable() {
+		t.Error("distro with OSTree markers was not detected as immutable")
+	}
+}
@@ -0,0 +1,56 @@
+//go:build go1.18
+// +build go1.18
+
+package linux
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// keyPattern matches the keys permitted in release files (eg ID or ro.build.version.release)
+var keyPattern = regexp.MustCompile("^[\\w.-]+$")
+
+func FuzzParseOSRelease(f *testing.F) {
+	f.Add([]byte("NAME=\"Ubuntu\"\nVERSION=\"20.04.1 LTS (Focal Fossa)\"\nID=ubuntu\nID_LIKE=debian\n" +
+		"PRETTY_NAME=\"Ubuntu 20.04.1 LTS\"\nVERSION_ID=\"20.04\"\nHOME_URL=\"https://www.ubuntu.com/\"\n"))
+	f.Add([]byte("DISTRIB_ID=Ubuntu\nDISTRIB_RELEASE=20.04\nDISTRIB_CODENAME=focal\n" +
+		"DISTRIB_DESCRIPTION=\"Ubuntu 20.04.1 LTS\"\n"))
+	f.Add([]byte("SUSE Linux Enterprise Server 11 (x86_64)\nVERSION = 11\nPATCHLEVEL = 4\n"))
+	f.Add([]byte("# comment\n\n   KEY\t =  'value'\r\nEMPTY=\nQUOTE=\"\nEQUALS=a=b\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		properties, _ := parseOSRelease(bytes.NewReader(data))
+		for key, val := range properties {
+			if !keyPattern.MatchString(key) {
+				t.Errorf("malformed key: %q", key)
+			}
+			if strings.ContainsAny(val, "\r\n") {
+				t.Errorf("value for key %q contains a line break: %q", key, val)
+			}
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			key, _, err := splitEqualsKeyVal(line)
+			if err == nil && key == "" {
+				t.Errorf("empty key returned without an error for line: %q", line)
+			}
+		}
+	})
+}
+
+func FuzzParseRedhatReleaseContents(f *testing.F) {
+	f.Add("Red Hat Enterprise Linux Server release 7.6 (Maipo)\n", "Red Hat")
+	f.Add("CentOS Linux release 8.2.2004 (Core)\n", "CentOS")
+	f.Add("Gentoo Base System version 1.6.14\n", "Gentoo")
+	f.Add("garbage", "Fedora")
+
+	f.Fuzz(func(t *testing.T, contents string, expectedDistro string) {
+		matched, version := parseRedhatReleaseContents(contents, expectedDistro)
+		if !matched && version != "" {
+			t.Errorf("version (%q) returned without a match", version)
+		}
+	})
+}
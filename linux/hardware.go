@@ -0,0 +1,17 @@
+package linux
+
+import (
+	"strings"
+)
+
+// detectHardwareModel returns the board model (eg "Raspberry Pi 4 Model B Rev 1.4") reported by the
+// device tree. An empty string is returned on systems that don't use a device tree.
+func (d *Detector) detectHardwareModel() string {
+	exists, contents := d.readFile("/proc/device-tree/model", "/sys/firmware/devicetree/base/model")
+	if !exists {
+		return ""
+	}
+
+	// Device tree strings are null terminated
+	return strings.TrimSpace(strings.TrimRight(contents, "\x00"))
+}
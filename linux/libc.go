@@ -0,0 +1,52 @@
+package linux
+
+// muslLoaderPattern matches the dynamic loader installed by musl libc for every architecture.
+const muslLoaderPattern = "/lib/ld-musl-*.so.1"
+
+// glibcPaths are the locations at which glibc is installed on the common architectures.
+var glibcPaths = []string{
+	"/lib/x86_64-linux-gnu/libc.so.6",
+	"/lib/aarch64-linux-gnu/libc.so.6",
+	"/lib64/libc.so.6",
+	"/lib/libc.so.6",
+}
+
+// muslDistroIds are the IDs of distros that are built against musl libc.
+var muslDistroIds = []string{"alpine", "chimera"}
+
+// detectLibc determines the C standard library used by the distro by looking for the files installed by
+// musl and glibc. Glibc based distros (eg Debian) may have the musl package installed alongside glibc,
+// so glibc wins when both are found unless the distro is known to use musl. When neither are found, the
+// libc is inferred from the distro ID for distros known to use musl. An empty string is returned when
+// the libc can't be determined.
+func (d *Detector) detectLibc(distro LinuxDistro) string {
+	muslDistro := false
+	for _, id := range muslDistroIds {
+		if distro.ID == id {
+			muslDistro = true
+			break
+		}
+	}
+
+	d.recordInspectedPaths([]string{muslLoaderPattern})
+	matches, err := d.glob(muslLoaderPattern)
+	hasMusl := err == nil && len(matches) > 0
+
+	d.recordInspectedPaths(glibcPaths)
+	hasGlibc := false
+	for _, glibcPath := range glibcPaths {
+		if _, err := d.stat(glibcPath); err == nil {
+			hasGlibc = true
+			break
+		}
+	}
+
+	if hasGlibc && !muslDistro {
+		return "glibc"
+	}
+	if hasMusl || muslDistro {
+		return "musl"
+	}
+
+	return ""
+}
@@ -0,0 +1,27 @@
+package linux
+
+// CanonicalIDs maps the IDs of distros that are reported under several IDs (eg the editions of
+// openSUSE) to a single canonical ID. The IDs are only mapped when Detector.NormalizeIDs is enabled.
+// Add to the map to normalize other IDs.
+var CanonicalIDs = map[string]string{
+	"ol":                  "oracle",
+	"opensuse-leap":       "opensuse",
+	"opensuse-tumbleweed": "opensuse",
+	"sled":                "sles",
+	"sles_sap":            "sles",
+	"archlinux":           "arch",
+}
+
+// normalizeID replaces the ID of the supplied distro with its canonical ID from CanonicalIDs while
+// keeping the original ID as the reported ID.
+func normalizeID(distro *LinuxDistro) {
+	canonicalID, ok := CanonicalIDs[distro.ID]
+	if !ok || canonicalID == distro.ID {
+		return
+	}
+
+	if distro.ReportedID == "" {
+		distro.ReportedID = distro.ID
+	}
+	distro.ID = canonicalID
+}
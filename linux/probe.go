@@ -0,0 +1,73 @@
+package linux
+
+import (
+	"bufio"
+	"strings"
+)
+
+// ProbedReleaseFiles are the well-known release files whose existence is reported by Probe. They are
+// the os-release locations followed by every other release file read by the distro tests and a few
+// generic release files.
+var ProbedReleaseFiles = append(append([]string{}, osReleasePaths...),
+	"/etc/lsb-release",
+	"/etc/redhat-release",
+	"/etc/redhat-version",
+	"/etc/centos-release",
+	"/etc/oracle-release",
+	"/etc/sl-release",
+	"/etc/clearos-release",
+	"/etc/nethserver-release",
+	"/etc/yellowdog-release",
+	"/etc/system-release",
+	"/etc/SuSE-release",
+	"/etc/sles-release",
+	"/etc/novell-release",
+	"/etc/debian_version",
+	"/etc/mx-version",
+	"/etc/rpi-issue",
+	"/etc/drbl/drbl.conf",
+	"/etc/alpine-release",
+	"/etc/gentoo-release",
+	"/etc/slackware-version",
+	"/etc/salix-version",
+	"/etc/zenwalk-version",
+	"/etc/mandriva-release",
+	"/etc/mandrake-release",
+	"/etc/sourcemage-release",
+	"/etc/photon-release",
+	"/system/build.prop",
+	"/etc/release",
+	"/etc/issue",
+)
+
+// ProbeResult describes whether a release file exists and the first line of its contents.
+type ProbeResult struct {
+	Path      string
+	Present   bool
+	FirstLine string
+}
+
+// Probe reports the existence and first line of each file in ProbedReleaseFiles under the detector's
+// root without attempting to identify the distro. This is useful for diagnosing why a distro wasn't
+// detected.
+func (d *Detector) Probe() []ProbeResult {
+	results := make([]ProbeResult, 0, len(ProbedReleaseFiles))
+
+	for _, filePath := range ProbedReleaseFiles {
+		result := ProbeResult{Path: filePath}
+
+		reader, _, err := readBinaryFileFunc(d, []string{filePath})
+		if err == nil {
+			result.Present = true
+			scanner := bufio.NewScanner(reader)
+			if scanner.Scan() {
+				result.FirstLine = strings.TrimSpace(scanner.Text())
+			}
+			_ = reader.Close()
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
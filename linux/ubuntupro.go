@@ -0,0 +1,33 @@
+package linux
+
+import "encoding/json"
+
+// ubuntuAdvantageStatusPath is written by the Ubuntu Pro client (ubuntu-advantage-tools). The client
+// also writes it on systems that aren't attached to an Ubuntu Pro (formerly ESM) subscription.
+const ubuntuAdvantageStatusPath = "/var/lib/ubuntu-advantage/status.json"
+
+// ubuntuAdvantageStatus contains the fields of the Ubuntu Pro status file that we inspect.
+type ubuntuAdvantageStatus struct {
+	Attached bool `json:"attached"`
+}
+
+// detectUbuntuPro returns true when the supplied distro is Ubuntu and the Ubuntu Pro status file
+// reports that the system is attached to a subscription.
+func (d *Detector) detectUbuntuPro(distro LinuxDistro) bool {
+	if distro.ID != "ubuntu" {
+		return false
+	}
+
+	exists, contents := d.readFile(ubuntuAdvantageStatusPath)
+	if !exists {
+		return false
+	}
+
+	var status ubuntuAdvantageStatus
+	if err := json.Unmarshal([]byte(contents), &status); err != nil {
+		d.warnf("unable to parse Ubuntu Pro status file (%s): %v", ubuntuAdvantageStatusPath, err)
+		return false
+	}
+
+	return status.Attached
+}
@@ -0,0 +1,67 @@
+package linux
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// osReleaseIDPattern matches the values of the os-release fields that are restricted to lowercase
+// letters, digits, ".", "_" and "-" (eg ID and VERSION_ID).
+var osReleaseIDPattern = regexp.MustCompile("^[a-z0-9._-]+$")
+
+// osReleaseIDFields are the os-release fields that are restricted to the characters of osReleaseIDPattern.
+var osReleaseIDFields = []string{"ID", "VERSION_ID", "VERSION_CODENAME", "VARIANT_ID", "IMAGE_ID"}
+
+// osReleaseNoSpaceFields are the os-release fields that may not contain whitespace.
+var osReleaseNoSpaceFields = []string{"BUILD_ID", "IMAGE_VERSION"}
+
+// osReleaseURLFields are the os-release fields that must contain a URL.
+var osReleaseURLFields = []string{"HOME_URL", "DOCUMENTATION_URL", "SUPPORT_URL", "BUG_REPORT_URL",
+	"PRIVACY_POLICY_URL"}
+
+// ValidateOSRelease checks the supplied os-release properties against the os-release specification
+// and returns a description of each violation found. An empty slice is returned for valid properties.
+// See: https://www.freedesktop.org/software/systemd/man/os-release.html
+func ValidateOSRelease(props ReleaseDetails) []string {
+	violations := []string{}
+
+	for _, key := range []string{"NAME", "ID"} {
+		if strings.TrimSpace(props[key]) == "" {
+			violations = append(violations, fmt.Sprintf("%s is missing", key))
+		}
+	}
+
+	for _, key := range osReleaseIDFields {
+		if value, ok := props[key]; ok && value != "" && !osReleaseIDPattern.MatchString(value) {
+			violations = append(violations, fmt.Sprintf(
+				"%s must only contain lowercase letters, digits, \".\", \"_\" and \"-\": %s", key, value))
+		}
+	}
+
+	for _, likeId := range strings.Fields(props["ID_LIKE"]) {
+		if !osReleaseIDPattern.MatchString(likeId) {
+			violations = append(violations, fmt.Sprintf(
+				"ID_LIKE must only contain IDs of lowercase letters, digits, \".\", \"_\" and \"-\": %s", likeId))
+		}
+	}
+
+	for _, key := range osReleaseNoSpaceFields {
+		if value := props[key]; strings.ContainsAny(value, " \t") {
+			violations = append(violations, fmt.Sprintf("%s must not contain whitespace: %s", key, value))
+		}
+	}
+
+	for _, key := range osReleaseURLFields {
+		value := props[key]
+		if value == "" {
+			continue
+		}
+		if parsed, err := url.Parse(value); err != nil || parsed.Scheme == "" {
+			violations = append(violations, fmt.Sprintf("%s must be a valid URL: %s", key, value))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,46 @@
+package linux
+
+import (
+	"strings"
+)
+
+// dmiVirtualization maps the DMI system vendor and product name prefixes reported by hypervisors to
+// the name of the virtualization technology. The names match those reported by systemd-detect-virt.
+// An empty prefix matches any value. Entries are checked in order.
+var dmiVirtualization = []struct {
+	vendorPrefix   string
+	productPrefix  string
+	virtualization string
+}{
+	{"", "KVM", "kvm"},
+	{"QEMU", "", "qemu"},
+	{"VMware", "", "vmware"},
+	{"", "VMware", "vmware"},
+	{"innotek GmbH", "", "oracle"},
+	{"", "VirtualBox", "oracle"},
+	{"Amazon EC2", "", "amazon"},
+	{"Xen", "", "xen"},
+	// Physical Surface devices also report Microsoft Corporation as the vendor
+	{"Microsoft Corporation", "Virtual Machine", "microsoft"},
+}
+
+// detectVirtualization returns the virtualization technology (eg "kvm" or "vmware") identified by the
+// DMI data. An empty string is returned on bare metal or when the hypervisor isn't known.
+func (d *Detector) detectVirtualization() string {
+	_, vendor := d.readFile("/sys/class/dmi/id/sys_vendor")
+	_, product := d.readFile("/sys/class/dmi/id/product_name")
+	vendor = strings.TrimSpace(vendor)
+	product = strings.TrimSpace(product)
+
+	if vendor == "" && product == "" {
+		return ""
+	}
+
+	for _, entry := range dmiVirtualization {
+		if strings.HasPrefix(vendor, entry.vendorPrefix) && strings.HasPrefix(product, entry.productPrefix) {
+			return entry.virtualization
+		}
+	}
+
+	return ""
+}
@@ -1,31 +1,111 @@
 package main
 
 import (
-	"encoding/json"
+	"encoding/csv"
 	"flag"
 	"fmt"
 	"github.com/dekobon/distro-detect/env"
 	"github.com/dekobon/distro-detect/linux"
+	"io"
 	"log"
 	"os"
+	"sort"
 	"strings"
 )
 
+// fieldAliases maps alternative field names accepted by the -fields flag to their canonical names.
+var fieldAliases = map[string]string{
+	"distro_id":      "id",
+	"distro_name":    "name",
+	"distro_version": "version",
+	"release":        "version",
+	"lsb":            "lsb_release",
+	"os":             "os_release",
+}
+
+// createOutputFile creates the file to which the output is written when the -out flag is set.
+var createOutputFile = func(outPath string) (io.WriteCloser, error) {
+	return os.Create(outPath)
+}
+
 func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) (exitCode int) {
 	var format string
 	var fields string
 	var fsRoot string
+	var hashFiles bool
+	var debug bool
+	var outPath string
+	var failIfEOL bool
+	var probe bool
+	var normalize bool
+	var allowExec bool
+
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&format, "format", "text", "Output format - valid values: text, text-no-labels, json, json-one-line, csv")
+	flags.StringVar(&fields, "fields", "", fieldsUsage())
+	flags.StringVar(&fsRoot, "fsroot", "/", "Path to the root of the filesystem in which to detect distro")
+	flags.BoolVar(&hashFiles, "hash-files", false, "Include the SHA-256 hashes of the release files read in the output")
+	flags.BoolVar(&debug, "debug", false, "Log the result of each distro detector and the files that it read")
+	flags.StringVar(&outPath, "out", "", "Path to a file to write the output to instead of stdout")
+	flags.BoolVar(&failIfEOL, "fail-if-eol", false, "Exit with a non-zero exit code when the detected distro version is end of life")
+	flags.BoolVar(&allowExec, "allow-exec", true, "Allow the detectors that read executables (eg BusyBox) to run")
+	flags.BoolVar(&normalize, "normalize", false, "Map the detected distro ID to its canonical ID (eg ol to oracle)")
+	flags.BoolVar(&probe, "probe", false, "List the well-known release files that exist along with their first line without detecting the distro")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
 
-	flag.StringVar(&format, "format", "text", "Output format - valid values: text, text-no-labels, json, json-one-line")
-	flag.StringVar(&fields, "fields", "", "Fields to output (comma separated) - valid values: name, id, version, lsb_release, os_release")
-	flag.StringVar(&fsRoot, "fsroot", "/", "Path to the root of the filesystem in which to detect distro")
+	logger := log.New(stderr, "error: ", 0)
+	warnLogger := log.New(stderr, "warn: ", 0)
 
-	flag.Parse()
+	output := stdout
+	if outPath != "" {
+		outFile, err := createOutputFile(outPath)
+		if err != nil {
+			logger.Printf("unable to open output file (%s): %v", outPath, err)
+			return -1
+		}
+		// Buffered writes may only fail when the file is closed (eg on a full disk)
+		defer func() {
+			if err := outFile.Close(); err != nil {
+				logger.Printf("unable to write output file (%s): %v", outPath, err)
+				exitCode = -1
+			}
+		}()
+		output = outFile
+	}
 
-	logger := log.New(os.Stderr, "error: ", 0)
+	detector := linux.WithRoot(fsRoot)
+	detector.HashReleaseFiles = hashFiles
+	detector.Debug = debug
+	detector.NormalizeIDs = normalize
+	detector.AllowExec = allowExec
 
-	linux.FileSystemRoot = fsRoot
-	distro := linux.DiscoverDistro()
+	if probe {
+		err := writeProbeResults(output, detector.Probe())
+		if err != nil {
+			logger.Println(err)
+			return -1
+		}
+
+		return 0
+	}
+
+	distro := detector.DiscoverDistro()
+
+	exitCode = 0
+	if failIfEOL && distro.IsEOL() {
+		eolDate, _ := distro.EOLDate()
+		logger.Printf("%s %s reached end of life on %s", distro.Name, distro.Version,
+			eolDate.Format("2006-01-02"))
+		exitCode = 1
+	}
 
 	// Plain text output
 	if format == "text" || format == "text-no-labels" {
@@ -36,48 +116,140 @@ func main() {
 			labelFormat = ""
 		}
 
-		if fields == "" {
-			err := distro.WriteAllResults(labelFormat, os.Stdout)
+		keys, unknownKeys := parseFields(fields)
+		for _, key := range unknownKeys {
+			warnLogger.Printf("ignoring unknown field: %s", key)
+		}
+
+		if keys == nil {
+			err := distro.WriteAllResults(labelFormat, output)
 			if err != nil {
 				logger.Println(err)
-				os.Exit(-1)
+				return -1
 			}
 		} else {
 			distroDetails := distro.AsMap()
-			segments := strings.Split(fields, ",")
-			for i := 0; i < len(segments); i++ {
-				key := strings.ToLower(strings.TrimSpace(segments[i]))
-
-				if distroDetails[segments[i]] != "" {
-					err := distro.WriteResult(labelFormat, key, os.Stdout)
+			for _, key := range keys {
+				if distroDetails[key] != "" {
+					err := distro.WriteResult(labelFormat, key, output)
 					if err != nil {
 						logger.Println(err)
-						os.Exit(-1)
+						return -1
 					}
 				}
 			}
 		}
 
-		os.Exit(0)
+		return exitCode
 	}
 
 	// JSON output
 	if format == "json" || format == "json-one-line" {
-		var jsonOutput []byte
-		var err error
-
-		if format == "json" {
-			jsonOutput, err = json.MarshalIndent(distro, "", "  ")
-		} else if format == "json-one-line" {
-			jsonOutput, err = json.Marshal(distro)
+		err := distro.WriteJSON(output, format == "json")
+		if err != nil {
+			logger.Println(err)
+			return -1
 		}
 
+		return exitCode
+	}
+
+	// CSV output
+	if format == "csv" {
+		err := writeCSV(output, distro)
 		if err != nil {
 			logger.Println(err)
-			os.Exit(-1)
+			return -1
+		}
+
+		return exitCode
+	}
+
+	return exitCode
+}
+
+// csvHeader contains the columns written by the csv output format.
+var csvHeader = []string{"id", "name", "version", "codename", "pretty_name"}
+
+// writeCSV writes a header row followed by a row for the supplied distro.
+func writeCSV(writer io.Writer, distro linux.LinuxDistro) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.UseCRLF = env.LineBreak == "\r\n"
+
+	if err := csvWriter.Write(csvHeader); err != nil {
+		return err
+	}
+	row := []string{distro.ID, distro.Name, distro.Version, distro.Codename(), distro.PrettyName}
+	if err := csvWriter.Write(row); err != nil {
+		return err
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeProbeResults writes a line for each of the supplied probe results.
+func writeProbeResults(writer io.Writer, results []linux.ProbeResult) error {
+	for _, result := range results {
+		var line string
+		if result.Present {
+			line = fmt.Sprintf("%s: present: %s", result.Path, result.FirstLine)
+		} else {
+			line = fmt.Sprintf("%s: absent", result.Path)
 		}
 
-		fmt.Printf("%s%s", jsonOutput, env.LineBreak)
-		os.Exit(0)
+		if _, err := io.WriteString(writer, line+env.LineBreak); err != nil {
+			return err
+		}
 	}
+
+	return nil
+}
+
+// fieldsUsage returns the usage of the -fields flag listing every field that can be output.
+func fieldsUsage() string {
+	keys := make([]string, 0, len(linux.DisplayKeys))
+	for key := range linux.DisplayKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return "Fields to output (comma separated) - valid values: all, " + strings.Join(keys, ", ")
+}
+
+// parseFields parses the comma separated value of the -fields flag into the canonical keys to output
+// and the keys that aren't known. A nil slice of keys indicates that all fields should be output.
+func parseFields(fields string) ([]string, []string) {
+	if strings.TrimSpace(fields) == "" {
+		return nil, nil
+	}
+
+	keys := []string{}
+	var unknownKeys []string
+	seen := map[string]bool{}
+
+	for _, segment := range strings.Split(fields, ",") {
+		key := strings.ToLower(strings.TrimSpace(segment))
+		if key == "" {
+			continue
+		}
+		if key == "all" {
+			return nil, unknownKeys
+		}
+		if alias, ok := fieldAliases[key]; ok {
+			key = alias
+		}
+		if _, ok := linux.DisplayKeys[key]; !ok {
+			unknownKeys = append(unknownKeys, key)
+			continue
+		}
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	return keys, unknownKeys
 }
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/dekobon/distro-detect/env"
+	"github.com/dekobon/distro-detect/linux"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFieldsAll(t *testing.T) {
+	root := ubuntuRoot(t)
+
+	var allOutput bytes.Buffer
+	if exitCode := run([]string{"-fsroot", root, "-fields", "all"}, &allOutput, ioutil.Discard); exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+	var defaultOutput bytes.Buffer
+	if exitCode := run([]string{"-fsroot", root}, &defaultOutput, ioutil.Discard); exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+
+	if !strings.Contains(allOutput.String(), "Distro ID: ubuntu") {
+		t.Errorf("output of all fields did not contain the distro id:\n%s", allOutput.String())
+	}
+	if len(allOutput.String()) != len(defaultOutput.String()) {
+		t.Errorf("output of all fields differs from the default output:\n%s\n%s", allOutput.String(),
+			defaultOutput.String())
+	}
+}
+
+func TestFieldsAliasesAndUnknown(t *testing.T) {
+	root := ubuntuRoot(t)
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	exitCode := run([]string{"-fsroot", root, "-fields", "id,release,bogus,ID", "-format", "text-no-labels"},
+		&stdout, &stderr)
+	if exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+
+	expected := "ubuntu" + env.LineBreak + "20.04" + env.LineBreak
+	if stdout.String() != expected {
+		t.Errorf("unexpected output. Expected:\n%s\nActual:\n%s", expected, stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "unknown field: bogus") {
+		t.Errorf("no warning was written for the unknown field: %s", stderr.String())
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	root := ubuntuRoot(t)
+	outPath := filepath.Join(t.TempDir(), "distro.json")
+
+	var stdout bytes.Buffer
+	if exitCode := run([]string{"-fsroot", root, "-format", "json"}, &stdout, ioutil.Discard); exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+	var fileStdout bytes.Buffer
+	exitCode := run([]string{"-fsroot", root, "-format", "json", "-out", outPath}, &fileStdout, ioutil.Discard)
+	if exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+
+	contents, err := ioutil.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The detection time differs between runs
+	if withoutDetectedAt(t, contents) != withoutDetectedAt(t, stdout.Bytes()) {
+		t.Errorf("output file differs from stdout. Expected:\n%s\nActual:\n%s", stdout.String(), contents)
+	}
+	if fileStdout.Len() != 0 {
+		t.Errorf("output was written to stdout when writing to a file: %s", fileStdout.String())
+	}
+}
+
+func TestOutputFileBadPath(t *testing.T) {
+	root := ubuntuRoot(t)
+	outPath := filepath.Join(t.TempDir(), "missing", "distro.json")
+
+	var stderr bytes.Buffer
+	exitCode := run([]string{"-fsroot", root, "-out", outPath}, ioutil.Discard, &stderr)
+	if exitCode == 0 {
+		t.Error("writing to a bad path did not fail")
+	}
+	if !strings.Contains(stderr.String(), "unable to open output file") {
+		t.Errorf("no error was written for the bad path: %s", stderr.String())
+	}
+}
+
+// failingCloseFile discards writes and fails when closed, like a file whose buffered writes can't be
+// flushed.
+type failingCloseFile struct {
+	io.Writer
+}
+
+func (f failingCloseFile) Close() error {
+	return errors.New("no space left on device")
+}
+
+func TestOutputFileCloseError(t *testing.T) {
+	originalCreateOutputFile := createOutputFile
+	createOutputFile = func(string) (io.WriteCloser, error) {
+		return failingCloseFile{Writer: ioutil.Discard}, nil
+	}
+	t.Cleanup(func() {
+		createOutputFile = originalCreateOutputFile
+	})
+
+	var stderr bytes.Buffer
+	exitCode := run([]string{"-fsroot", ubuntuRoot(t), "-out", "distro.txt"}, ioutil.Discard, &stderr)
+	if exitCode == 0 {
+		t.Error("failing to close the output file did not fail")
+	}
+	if !strings.Contains(stderr.String(), "unable to write output file") {
+		t.Errorf("no error was written for the failed close: %s", stderr.String())
+	}
+}
+
+func TestCSVOutput(t *testing.T) {
+	root := ubuntuRoot(t)
+
+	var stdout bytes.Buffer
+	if exitCode := run([]string{"-fsroot", root, "-format", "csv"}, &stdout, ioutil.Discard); exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+
+	records, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"id", "name", "version", "codename", "pretty_name"},
+		{"ubuntu", "Ubuntu", "20.04", "focal", "Ubuntu 20.04.1 LTS"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("unexpected CSV records. Expected:\n%v\nActual:\n%v", expected, records)
+	}
+}
+
+func TestFailIfEOL(t *testing.T) {
+	originalEOLDates := linux.EOLDates
+	linux.EOLDates = map[string]map[string]string{
+		"centos": {"8": "2021-12-31"},
+	}
+	t.Cleanup(func() {
+		linux.EOLDates = originalEOLDates
+	})
+
+	centosRoot := t.TempDir()
+	writeTestFile(t, centosRoot, "/etc/os-release", "NAME=\"CentOS Linux\"\nVERSION=\"8\"\nID=\"centos\"\nVERSION_ID=\"8\"\n")
+	writeTestFile(t, centosRoot, "/etc/centos-release", "CentOS Linux release 8.5.2111\n")
+
+	var stderr bytes.Buffer
+	exitCode := run([]string{"-fsroot", centosRoot, "-fail-if-eol"}, ioutil.Discard, &stderr)
+	if exitCode != 1 {
+		t.Errorf("unexpected exit code for an end of life distro: %d", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "CentOS Linux 8.5.2111 reached end of life on 2021-12-31") {
+		t.Errorf("no end of life message was written: %s", stderr.String())
+	}
+
+	exitCode = run([]string{"-fsroot", ubuntuRoot(t), "-fail-if-eol"}, ioutil.Discard, ioutil.Discard)
+	if exitCode != 0 {
+		t.Errorf("unexpected exit code for a supported distro: %d", exitCode)
+	}
+	exitCode = run([]string{"-fsroot", centosRoot}, ioutil.Discard, ioutil.Discard)
+	if exitCode != 0 {
+		t.Errorf("unexpected exit code without -fail-if-eol: %d", exitCode)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.13.2\n")
+	writeTestFile(t, root, "/etc/alpine-release", "3.13.2\n")
+
+	var stdout bytes.Buffer
+	if exitCode := run([]string{"-fsroot", root, "-probe"}, &stdout, ioutil.Discard); exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+
+	for _, expected := range []string{
+		"/etc/os-release: present: NAME=\"Alpine Linux\"" + env.LineBreak,
+		"/etc/alpine-release: present: 3.13.2" + env.LineBreak,
+		"/run/os-release: absent" + env.LineBreak,
+		"/etc/lsb-release: absent" + env.LineBreak,
+	} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("probe output did not contain (%s):\n%s", strings.TrimSpace(expected), stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "Distro ID") {
+		t.Errorf("probe output unexpectedly contained the detected distro:\n%s", stdout.String())
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	leapRoot := t.TempDir()
+	writeTestFile(t, leapRoot, "/etc/os-release", "NAME=\"openSUSE Leap\"\nVERSION=\"15.5\"\nID=\"opensuse-leap\"\nID_LIKE=\"suse opensuse\"\nVERSION_ID=\"15.5\"\n")
+	oracleRoot := t.TempDir()
+	writeTestFile(t, oracleRoot, "/etc/os-release", "NAME=\"Oracle Linux Server\"\nVERSION=\"8.9\"\nID=\"ol\"\nID_LIKE=\"fedora\"\nVERSION_ID=\"8.9\"\n")
+
+	tests := []struct {
+		root       string
+		normalized string
+		native     string
+	}{
+		{root: leapRoot, normalized: "opensuse", native: "opensuse-leap"},
+		{root: oracleRoot, normalized: "oracle", native: "ol"},
+	}
+
+	for _, test := range tests {
+		var stdout bytes.Buffer
+		exitCode := run([]string{"-fsroot", test.root, "-normalize", "-fields", "id", "-format", "text-no-labels"},
+			&stdout, ioutil.Discard)
+		if exitCode != 0 {
+			t.Fatalf("unexpected exit code: %d", exitCode)
+		}
+		if stdout.String() != test.normalized+env.LineBreak {
+			t.Errorf("ID was not normalized. Expected (%s) was (%s).", test.normalized,
+				strings.TrimSpace(stdout.String()))
+		}
+
+		stdout.Reset()
+		exitCode = run([]string{"-fsroot", test.root, "-fields", "id", "-format", "text-no-labels"},
+			&stdout, ioutil.Discard)
+		if exitCode != 0 {
+			t.Fatalf("unexpected exit code: %d", exitCode)
+		}
+		if stdout.String() != test.native+env.LineBreak {
+			t.Errorf("ID was normalized without the -normalize flag. Expected (%s) was (%s).", test.native,
+				strings.TrimSpace(stdout.String()))
+		}
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	keys, unknownKeys := parseFields("")
+	if keys != nil || unknownKeys != nil {
+		t.Errorf("empty fields should select all fields: %v %v", keys, unknownKeys)
+	}
+
+	keys, _ = parseFields(" Name , distro_id,all")
+	if keys != nil {
+		t.Errorf("the all field should select all fields: %v", keys)
+	}
+
+	keys, unknownKeys = parseFields("bogus")
+	if keys == nil || len(keys) != 0 {
+		t.Errorf("only unknown fields should select no fields: %v", keys)
+	}
+	if len(unknownKeys) != 1 || unknownKeys[0] != "bogus" {
+		t.Errorf("unexpected unknown fields: %v", unknownKeys)
+	}
+}
+
+func TestFieldsUsageListsEveryField(t *testing.T) {
+	usage := fieldsUsage()
+	for key := range linux.DisplayKeys {
+		if !strings.Contains(usage, " "+key) {
+			t.Errorf("the -fields usage doesn't list the field (%s): %s", key, usage)
+		}
+	}
+}
+
+func TestJSONDetectorVersion(t *testing.T) {
+	root := ubuntuRoot(t)
+
+	var stdout bytes.Buffer
+	if exitCode := run([]string{"-fsroot", root, "-format", "json"}, &stdout, ioutil.Discard); exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+
+	var distro linux.LinuxDistro
+	if err := json.Unmarshal(stdout.Bytes(), &distro); err != nil {
+		t.Fatal(err)
+	}
+	if distro.DetectorVersion != linux.Version {
+		t.Errorf("detector version was not reported correctly. Expected (%s) was (%s).", linux.Version,
+			distro.DetectorVersion)
+	}
+	if distro.DetectedAt.IsZero() {
+		t.Error("detection time was not reported")
+	}
+}
+
+func TestJSONOneLineKeyOrder(t *testing.T) {
+	root := ubuntuRoot(t)
+
+	var stdout bytes.Buffer
+	if exitCode := run([]string{"-fsroot", root, "-format", "json-one-line"}, &stdout, ioutil.Discard); exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+
+	output := stdout.String()
+	if !strings.HasPrefix(output, `{"id":"ubuntu",`) {
+		t.Errorf("JSON output did not start with the id:\n%s", output)
+	}
+
+	previousIndex := -1
+	for _, key := range []string{`"id"`, `"name"`, `"version"`, `"pretty_name"`, `"detected_at"`, `"lsb_release"`, `"os_release"`} {
+		index := strings.Index(output, key)
+		if index <= previousIndex {
+			t.Errorf("key (%s) was out of order in the JSON output:\n%s", key, output)
+		}
+		previousIndex = index
+	}
+}
+
+func TestTextOmitsDetectedAt(t *testing.T) {
+	root := ubuntuRoot(t)
+
+	var stdout bytes.Buffer
+	if exitCode := run([]string{"-fsroot", root}, &stdout, ioutil.Discard); exitCode != 0 {
+		t.Fatalf("unexpected exit code: %d", exitCode)
+	}
+
+	if strings.Contains(stdout.String(), "Detected At") || strings.Contains(stdout.String(), "Detector Version") {
+		t.Errorf("text output unexpectedly contained the detection details:\n%s", stdout.String())
+	}
+}
+
+// withoutDetectedAt returns the supplied JSON with the detected_at value removed.
+func withoutDetectedAt(t *testing.T, jsonOutput []byte) string {
+	var values map[string]interface{}
+	if err := json.Unmarshal(jsonOutput, &values); err != nil {
+		t.Fatal(err)
+	}
+	delete(values, "detected_at")
+
+	normalized, err := json.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(normalized)
+}
+
+func ubuntuRoot(t *testing.T) string {
+	root := t.TempDir()
+	writeTestFile(t, root, "/etc/lsb-release", "DISTRIB_ID=Ubuntu\nDISTRIB_RELEASE=20.04\nDISTRIB_CODENAME=focal\nDISTRIB_DESCRIPTION=\"Ubuntu 20.04.1 LTS\"\n")
+	writeTestFile(t, root, "/etc/os-release", "NAME=\"Ubuntu\"\nVERSION=\"20.04.1 LTS (Focal Fossa)\"\nID=ubuntu\nID_LIKE=debian\nPRETTY_NAME=\"Ubuntu 20.04.1 LTS\"\nVERSION_ID=\"20.04\"\nVERSION_CODENAME=focal\nUBUNTU_CODENAME=focal\n")
+	return root
+}
+
+func writeTestFile(t *testing.T, root string, filePath string, contents string) {
+	fullPath := filepath.Join(root, filepath.FromSlash(filePath))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(fullPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}